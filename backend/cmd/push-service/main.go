@@ -0,0 +1,98 @@
+// Command push-service is a local stand-in for a Web Push service. POST
+// /subscriptions returns a browser-style PushSubscription whose endpoint points
+// back here; pushes to it are checked for a valid VAPID signature, decrypted
+// with the subscription's keys and printed. DELETE /subscriptions/{id} expires
+// a subscription so the server's cleanup can be observed (pushes get 410).
+//
+// With -gen-vapid it prints a fresh VAPID key pair for the server and exits.
+package main
+
+import (
+    "crypto/ecdh"
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "strings"
+    "sync"
+
+    "github.com/google/uuid"
+
+    "chorequest/backend/internal/push"
+)
+
+type subscription struct {
+    key  *ecdh.PrivateKey
+    auth []byte
+}
+
+func main() {
+    addr := flag.String("addr", ":9091", "listen address")
+    base := flag.String("base-url", "http://localhost:9091", "URL the server reaches this service at")
+    genVAPID := flag.Bool("gen-vapid", false, "print a VAPID key pair and exit")
+    flag.Parse()
+    if *genVAPID {
+        public, private, err := push.GenerateVAPIDKeys()
+        if err != nil { log.Fatal(err) }
+        fmt.Printf("VAPID_PUBLIC_KEY=%s\nVAPID_PRIVATE_KEY=%s\n", public, private)
+        return
+    }
+
+    var mu sync.Mutex
+    subs := map[string]*subscription{}
+    b64 := base64.RawURLEncoding
+
+    http.HandleFunc("POST /subscriptions", func(w http.ResponseWriter, r *http.Request) {
+        k, err := ecdh.P256().GenerateKey(rand.Reader)
+        if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+        s := &subscription{key: k, auth: make([]byte, 16)}
+        _, _ = rand.Read(s.auth)
+        id := uuid.NewString()
+        mu.Lock()
+        subs[id] = s
+        mu.Unlock()
+        w.Header().Set("Content-Type", "application/json")
+        _ = json.NewEncoder(w).Encode(map[string]any{
+            "endpoint": strings.TrimSuffix(*base, "/") + "/push/" + id,
+            "keys":     map[string]string{"p256dh": b64.EncodeToString(k.PublicKey().Bytes()), "auth": b64.EncodeToString(s.auth)},
+        })
+    })
+
+    http.HandleFunc("DELETE /subscriptions/{id}", func(w http.ResponseWriter, r *http.Request) {
+        mu.Lock()
+        delete(subs, r.PathValue("id"))
+        mu.Unlock()
+        w.WriteHeader(http.StatusNoContent)
+    })
+
+    http.HandleFunc("POST /push/{id}", func(w http.ResponseWriter, r *http.Request) {
+        id := r.PathValue("id")
+        mu.Lock()
+        s := subs[id]
+        mu.Unlock()
+        if s == nil { http.Error(w, "unknown subscription", http.StatusGone); return }
+        if err := push.VerifyAuthorization(r.Header.Get("Authorization"), strings.TrimSuffix(*base, "/")); err != nil {
+            log.Printf("rejected push for %s: %v", id, err)
+            http.Error(w, err.Error(), http.StatusUnauthorized)
+            return
+        }
+        if r.Header.Get("Content-Encoding") != "aes128gcm" { http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType); return }
+        body, err := io.ReadAll(io.LimitReader(r.Body, 4097))
+        if err != nil || len(body) > 4096 { http.Error(w, "payload too large", http.StatusRequestEntityTooLarge); return }
+        msg, err := push.Decrypt(s.key, s.auth, body)
+        if err != nil {
+            log.Printf("undecryptable push for %s: %v", id, err)
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        log.Printf("push %s (TTL %s, urgency %s): %s", id, r.Header.Get("TTL"), r.Header.Get("Urgency"), msg)
+        w.WriteHeader(http.StatusCreated)
+    })
+
+    log.Printf("push service stand-in listening on %s", *addr)
+    log.Fatal(http.ListenAndServe(*addr, nil))
+}
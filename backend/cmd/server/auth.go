@@ -0,0 +1,170 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/mail"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    appauth "chorequest/backend/internal/auth"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// parseRole accepts PARENT or CHILD in any case; an empty role means PARENT.
+func parseRole(s string) (model.Role, bool) {
+    if s == "" { return model.RoleParent, true }
+    role := model.Role(strings.ToUpper(s))
+    return role, role.IsValid()
+}
+
+// writeLogin finishes a first-factor login for sub. Any login endpoint must
+// answer through it so that parents with TOTP enabled always get the MFA
+// challenge instead of a full token.
+func writeLogin(w http.ResponseWriter, r *http.Request, secret string, ttl time.Duration, repo repopkg.Repo, sub string, role model.Role) {
+    if role != model.RoleChild {
+        if repo == nil {
+            http.Error(w, "login unavailable", http.StatusPreconditionFailed)
+            return
+        }
+        st, err := repo.GetMFA(r.Context(), sub)
+        if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+        if st.EnabledAt != nil {
+            writeMFAChallenge(w, secret, sub)
+            return
+        }
+    }
+    writeToken(w, secret, ttl, sub, role)
+}
+
+// writeToken answers with a full token for sub; only writeLogin and the MFA
+// step may call it.
+func writeToken(w http.ResponseWriter, secret string, ttl time.Duration, sub string, role model.Role) {
+    t, err := appauth.IssueToken(secret, sub, string(role), ttl)
+    if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+    w.Header().Set("Content-Type", "application/json")
+    _ = json.NewEncoder(w).Encode(map[string]string{"token": t, "sub": sub})
+}
+
+// writeMFAChallenge answers a first-factor login for a parent with TOTP enabled.
+func writeMFAChallenge(w http.ResponseWriter, secret, sub string) {
+    t, err := appauth.IssueMFAPendingToken(secret, sub)
+    if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+    w.Header().Set("Content-Type", "application/json")
+    _ = json.NewEncoder(w).Encode(map[string]any{"mfaRequired": true, "mfaToken": t})
+}
+
+// mfaLoginHandler exchanges an MFA-pending token plus a TOTP or recovery code
+// for a full parent JWT. Body: {"mfaToken": "...", "code": "123456"}.
+func mfaLoginHandler(secret string, ttl time.Duration, repo repopkg.Repo) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if secret == "" || repo == nil {
+            http.Error(w, "mfa login unavailable", http.StatusPreconditionFailed)
+            return
+        }
+        var body struct {
+            MFAToken string `json:"mfaToken"`
+            Code     string `json:"code"`
+        }
+        if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&body); err != nil {
+            http.Error(w, "invalid request body", http.StatusBadRequest)
+            return
+        }
+        sub, err := appauth.ParseMFAPendingToken(secret, body.MFAToken)
+        if err != nil { http.Error(w, err.Error(), http.StatusUnauthorized); return }
+        st, err := repo.GetMFA(r.Context(), sub)
+        if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+        if st.EnabledAt == nil || !appauth.VerifySecondFactor(r.Context(), repo, sub, st.Secret, body.Code) {
+            http.Error(w, "invalid verification code", http.StatusUnauthorized)
+            return
+        }
+        writeToken(w, secret, ttl, sub, model.RoleParent)
+    }
+}
+
+// Password length bounds for parent logins; the upper bound keeps hashing cheap to reject.
+const (
+    minPasswordLen = 10
+    maxPasswordLen = 128
+)
+
+type credentials struct {
+    Email    string `json:"email"`
+    Password string `json:"password"`
+}
+
+// readCredentials decodes {"email", "password"} and normalizes the email.
+func readCredentials(w http.ResponseWriter, r *http.Request) (credentials, bool) {
+    var c credentials
+    if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&c); err != nil {
+        http.Error(w, "invalid request body", http.StatusBadRequest)
+        return c, false
+    }
+    addr, err := mail.ParseAddress(strings.TrimSpace(c.Email))
+    if err != nil || addr.Name != "" {
+        http.Error(w, "invalid email", http.StatusBadRequest)
+        return c, false
+    }
+    c.Email = strings.ToLower(addr.Address)
+    if len(c.Password) > maxPasswordLen {
+        http.Error(w, "password is too long", http.StatusBadRequest)
+        return c, false
+    }
+    return c, true
+}
+
+// signupHandler creates a parent account. Body: {"email": "...", "password": "..."}.
+// It answers like passwordLoginHandler, with a token for the new parent ID.
+func signupHandler(secret string, ttl time.Duration, repo repopkg.Repo) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if secret == "" || repo == nil {
+            http.Error(w, "signup unavailable", http.StatusPreconditionFailed)
+            return
+        }
+        c, ok := readCredentials(w, r)
+        if !ok { return }
+        if len(c.Password) < minPasswordLen {
+            http.Error(w, fmt.Sprintf("password must be at least %d characters", minPasswordLen), http.StatusBadRequest)
+            return
+        }
+        hash, err := appauth.HashPassword(c.Password)
+        if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+        parentID := uuid.NewString()
+        if err := repo.CreateLogin(r.Context(), c.Email, parentID, hash); err != nil {
+            if apperr.Is(err, apperr.Conflict) { http.Error(w, err.Error(), http.StatusConflict); return }
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        writeLogin(w, r, secret, ttl, repo, parentID, model.RoleParent)
+    }
+}
+
+// passwordLoginHandler is the parent login. Body: {"email": "...", "password": "..."}.
+// It finishes through writeLogin, so parents with TOTP enabled get the MFA
+// challenge rather than a token.
+func passwordLoginHandler(secret string, ttl time.Duration, repo repopkg.Repo) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if secret == "" || repo == nil {
+            http.Error(w, "login unavailable", http.StatusPreconditionFailed)
+            return
+        }
+        c, ok := readCredentials(w, r)
+        if !ok { return }
+        l, err := repo.GetLogin(r.Context(), c.Email)
+        if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+        hash := ""
+        if l != nil { hash = l.PasswordHash }
+        // CheckPassword does the full derivation even without a login, so
+        // response times do not reveal which emails have accounts.
+        if !appauth.CheckPassword(hash, c.Password) || l == nil {
+            http.Error(w, "invalid email or password", http.StatusUnauthorized)
+            return
+        }
+        writeLogin(w, r, secret, ttl, repo, l.ParentID, model.RoleParent)
+    }
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "log"
+    "net/http"
+    "time"
+
+    "github.com/99designs/gqlgen/graphql/handler"
+    "github.com/99designs/gqlgen/graphql/handler/extension"
+    "github.com/99designs/gqlgen/graphql/handler/lru"
+    "github.com/99designs/gqlgen/graphql/handler/transport"
+    "github.com/gorilla/websocket"
+    "github.com/vektah/gqlparser/v2/ast"
+
+    "chorequest/backend/graph"
+    appauth "chorequest/backend/internal/auth"
+    "chorequest/backend/internal/config"
+)
+
+// newGraphQLServer mirrors handler.NewDefaultServer, adding an authenticated
+// WebSocket transport for subscriptions, query depth/complexity limits and an
+// optional persisted-operation allowlist. Introspection and GET are dev-only.
+func newGraphQLServer(cfg *config.Config, resolver *graph.Resolver, mutations mutationRateLimit) (*handler.Server, error) {
+    srv := handler.New(graph.NewExecutableSchema(graph.NewConfig(resolver)))
+
+    srv.AddTransport(transport.Websocket{
+        KeepAlivePingInterval: 10 * time.Second,
+        Upgrader: websocket.Upgrader{
+            CheckOrigin: func(r *http.Request) bool { return originAllowed(cfg.CORSOrigins, r.Header.Get("Origin")) },
+        },
+        // Browsers cannot set headers on WebSocket upgrades, so the JWT travels in the
+        // init payload. Subscriptions always need a signed-in caller, so a connection
+        // without a valid token is closed before any subscribe message.
+        InitFunc: func(ctx context.Context, p transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+            ctx, ok := appauth.WithBearer(ctx, cfg.JWTSecret, p.Authorization())
+            if !ok {
+                return ctx, nil, errors.New("invalid authorization token")
+            }
+            return ctx, &p, nil
+        },
+    })
+    srv.AddTransport(transport.Options{})
+    if cfg.DevToolsEnabled() {
+        srv.AddTransport(transport.GET{})
+    }
+    srv.AddTransport(transport.POST{})
+    srv.AddTransport(transport.MultipartForm{})
+
+    srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+    srv.SetErrorPresenter(graph.ErrorPresenter)
+
+    if cfg.DevToolsEnabled() {
+        srv.Use(extension.Introspection{})
+    }
+    allowlist, err := graph.LoadAllowlist(cfg.AllowlistFile, cfg.AllowlistOnly, lru.New[string](100))
+    if err != nil {
+        return nil, err
+    }
+    srv.Use(allowlist)
+    srv.Use(extension.AutomaticPersistedQuery{
+        Cache: allowlist,
+    })
+    if cfg.ComplexityLimit > 0 {
+        srv.Use(extension.FixedComplexityLimit(cfg.ComplexityLimit))
+    }
+    srv.Use(graph.DepthLimit{Max: cfg.DepthLimit})
+    srv.Use(mutations)
+    srv.Use(graph.Idempotency{Store: resolver.Repo})
+
+    if cfg.AllowlistFile != "" {
+        log.Printf("graphql allowlist: %d operations (enforce=%v)", allowlist.Len(), cfg.AllowlistOnly)
+    }
+    return srv, nil
+}
+
+// originAllowed applies the CORS origin list to WebSocket upgrades. Requests
+// without an Origin header come from non-browser clients and are allowed.
+func originAllowed(allowed []string, origin string) bool {
+    if origin == "" {
+        return true
+    }
+    for _, o := range allowed {
+        if o == "*" || o == origin {
+            return true
+        }
+    }
+    return false
+}
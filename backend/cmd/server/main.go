@@ -4,38 +4,54 @@ import (
     "context"
     "log"
     "net/http"
-    "os"
     "time"
 
-    "github.com/99designs/gqlgen/graphql/handler"
     "github.com/99designs/gqlgen/graphql/playground"
     "github.com/go-chi/chi/v5"
     "github.com/go-chi/chi/v5/middleware"
     "github.com/go-chi/cors"
 
     appauth "chorequest/backend/internal/auth"
+    "chorequest/backend/internal/billing"
     "chorequest/backend/graph"
+    "chorequest/backend/internal/config"
     "chorequest/backend/internal/db"
+    "chorequest/backend/internal/entitlements"
+    "chorequest/backend/internal/events"
+    "chorequest/backend/internal/notify"
+    "chorequest/backend/internal/outbound"
+    "chorequest/backend/internal/outbox"
+    "chorequest/backend/internal/push"
+    "chorequest/backend/internal/ratelimit"
+    "chorequest/backend/internal/rest"
     repopkg "chorequest/backend/internal/repo"
-    "github.com/golang-jwt/jwt/v5"
+    "chorequest/backend/internal/savings"
+    "chorequest/backend/internal/schedule"
+    "chorequest/backend/internal/webhook"
     "github.com/joho/godotenv"
+    "github.com/stripe/stripe-go/v76/client"
 )
 
 func main() {
     // Load environment from .env if present (for local dev)
     _ = godotenv.Load()
 
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatalf("config: %v", err)
+    }
+
     r := chi.NewRouter()
     r.Use(middleware.RequestID)
-    r.Use(middleware.RealIP)
+    r.Use(realIP(cfg.TrustedProxies))
     r.Use(middleware.Logger)
     r.Use(middleware.Recoverer)
     r.Use(cors.Handler(cors.Options{
-        AllowedOrigins:   []string{"http://localhost:5173", "http://127.0.0.1:5173", "*"},
+        AllowedOrigins:   cfg.CORSOrigins,
         AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-        ExposedHeaders:   []string{"Link"},
-        AllowCredentials: true,
+        AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", "Idempotency-Key"},
+        ExposedHeaders:   []string{"Link", "Retry-After", "Idempotent-Replay"},
+        AllowCredentials: cfg.CORSCredentials,
         MaxAge:           300,
     }))
 
@@ -45,49 +61,113 @@ func main() {
         _, _ = w.Write([]byte("ok"))
     })
 
-    // Dev auth endpoint: issues a JWT with sub + role for quick testing
-    r.Post("/auth/dev", func(w http.ResponseWriter, r *http.Request) {
-        secret := os.Getenv("JWT_SECRET")
-        if secret == "" {
-            http.Error(w, "JWT_SECRET not set", http.StatusPreconditionFailed)
-            return
-        }
-        role := r.URL.Query().Get("role")
-        if role == "" { role = "PARENT" }
-        sub := r.URL.Query().Get("sub")
-        if sub == "" { sub = "dev-user" }
-        token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-            "sub":  sub,
-            "role": role,
-            "iat":  time.Now().Unix(),
-        })
-        s, err := token.SignedString([]byte(secret))
-        if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
-        w.Header().Set("Content-Type", "application/json")
-        _, _ = w.Write([]byte("{\"token\":\"" + s + "\"}"))
-    })
-
     // Dependencies
     dbClient, err := db.New(context.Background())
     if err != nil {
         log.Printf("dynamo client error (non-fatal): %v", err)
     }
     var appRepo repopkg.Repo
+    bus := events.NewBus()
+    pusher, err := pushClient(cfg)
+    if err != nil {
+        log.Fatalf("push: %v", err)
+    }
     if dbClient != nil {
-        appRepo = repopkg.NewDynamoRepo(dbClient.Dynamo, os.Getenv("DYNAMO_TABLE_NAME"))
+        dynamo := repopkg.NewDynamoRepo(dbClient.Dynamo, cfg.TableName)
+        // Events committed by the repo reach webhooks, email and subscribers through the outbox.
+        hooks := webhook.NewDispatcher(dynamo)
+        if cfg.DevToolsEnabled() {
+            // Let dev deliver to local receivers such as cmd/webhook-receiver.
+            hooks.Client = outbound.NewClient(0, false)
+        }
+        mail := notify.NewNotifier(dynamo, mailSender(cfg))
+        relay := outbox.NewDispatcher(dynamo, bus)
+        relay.Handlers = append(relay.Handlers, hooks.Enqueue, mail.Handle)
+        if pusher != nil {
+            relay.Handlers = append(relay.Handlers, push.NewNotifier(dynamo, pusher).Handle)
+        }
+        dynamo.OnCommit = relay.Wake
+        go relay.Run(context.Background())
+        go hooks.Run(context.Background())
+        go mail.RunDigests(context.Background())
+        go schedule.NewRunner(dynamo, entitlements.New(dynamo, cfg)).Run(context.Background())
+        go savings.NewScheduler(dynamo).Run(context.Background())
+        appRepo = dynamo
+    }
+
+    // Rate limits: auth endpoints, all /query requests and GraphQL mutations have separate budgets.
+    limits := ratelimit.NewMemory()
+    authLimit := ratelimit.Middleware(limits, "auth", perMinute(cfg.RateLimitAuth), rateKey)
+
+    // Dev auth endpoint: issues a JWT with sub + role for quick testing (only mounted in dev)
+    if cfg.DevToolsEnabled() {
+        r.With(authLimit).Post("/auth/dev", func(w http.ResponseWriter, r *http.Request) {
+            secret := cfg.JWTSecret
+            if secret == "" {
+                http.Error(w, "JWT_SECRET not set", http.StatusPreconditionFailed)
+                return
+            }
+            role, ok := parseRole(r.URL.Query().Get("role"))
+            if !ok {
+                http.Error(w, "role must be PARENT or CHILD", http.StatusBadRequest)
+                return
+            }
+            sub := r.URL.Query().Get("sub")
+            if sub == "" { sub = "dev-user" }
+            writeLogin(w, r, secret, cfg.JWTTTL, appRepo, sub, role)
+        })
     }
 
+    // Parent email/password accounts, and the second login step for parents with TOTP enabled
+    r.With(authLimit).Post("/auth/signup", signupHandler(cfg.JWTSecret, cfg.JWTTTL, appRepo))
+    r.With(authLimit).Post("/auth/login", passwordLoginHandler(cfg.JWTSecret, cfg.JWTTTL, appRepo))
+    r.With(authLimit).Post("/auth/mfa", mfaLoginHandler(cfg.JWTSecret, cfg.JWTTTL, appRepo))
+
     // GraphQL endpoint (gqlgen)
-    gql := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{Repo: appRepo}}))
-    r.Method("POST", "/query", gql)
-    r.Method("GET", "/query", gql) // allow GET for basic tests
-    // GraphQL Playground (legacy) — keep available for reference
-    r.Get("/play", func(w http.ResponseWriter, r *http.Request) {
-        playground.Handler("GraphQL", "/query").ServeHTTP(w, r)
+    resolver := &graph.Resolver{Repo: appRepo, Events: bus, Config: cfg, Push: pusher}
+    if cfg.StripeSecret != "" {
+        resolver.Stripe = client.New(cfg.StripeSecret, nil)
+    }
+    mutations := mutationRateLimit{store: limits, limit: perMinute(cfg.RateLimitMutations)}
+    gql, err := newGraphQLServer(cfg, resolver, mutations)
+    if err != nil {
+        log.Fatalf("graphql: %v", err)
+    }
+    // Bearer JWTs and API keys both populate the caller identity; neither is
+    // required. Failed checks are limited per IP.
+    authn := authFailureLimit(limits, perMinute(cfg.RateLimitAuthFailures), func(h http.Handler) http.Handler {
+        return appauth.JWTMiddleware(cfg.JWTSecret)(appauth.APIKeyMiddleware(appRepo)(h))
     })
+    queryLimit := ratelimit.Middleware(limits, "request", perMinute(cfg.RateLimitRequests), rateKey)
+    query := authn(withRateKey(queryLimit(gql)))
+    r.Method("POST", "/query", query)
+    // GET carries WebSocket upgrades; plain GET queries are only served in dev.
+    r.Method("GET", "/query", query)
+
+    // REST API for integrations that cannot speak GraphQL; same resolvers, auth and budgets.
+    api, err := rest.New(resolver)
+    if err != nil {
+        log.Fatalf("rest: %v", err)
+    }
+    // Writes spend the GraphQL mutation budget and honour Idempotency-Key like mutations.
+    api.Idempotency = graph.Idempotency{Store: appRepo}
+    api.WriteLimit = func(r *http.Request) (time.Duration, bool) { return mutations.allow(r.Context(), rateKey(r)) }
+    r.Mount("/api/v1", authn(queryLimit(api.Handler())))
+
+    // Stripe events keep subscription state current; authenticated by signature.
+    if appRepo != nil {
+        r.Method("POST", "/webhooks/stripe", &billing.WebhookHandler{Store: appRepo, Secret: cfg.StripeWebhookSecret})
+    }
+
+    // GraphQL Playground (legacy) — keep available for reference in dev
+    if cfg.DevToolsEnabled() {
+        r.Get("/play", func(w http.ResponseWriter, r *http.Request) {
+            playground.Handler("GraphQL", "/query").ServeHTTP(w, r)
+        })
+    }
 
     // Optional GraphiQL UI in dev: enable by setting ENABLE_GRAPHIQL=1
-    if os.Getenv("ENABLE_GRAPHIQL") == "1" {
+    if cfg.DevToolsEnabled() && cfg.EnableGraphiQL {
         r.Get("/graphiql", func(w http.ResponseWriter, r *http.Request) {
             w.Header().Set("Content-Type", "text/html; charset=utf-8")
             _, _ = w.Write([]byte(graphiqlHTML))
@@ -96,7 +176,7 @@ func main() {
 
     // Example protected route using JWT middleware (not required yet by schema)
     r.Group(func(pr chi.Router) {
-        pr.Use(appauth.JWTMiddleware(os.Getenv("JWT_SECRET")))
+        pr.Use(authn)
         pr.Get("/me", func(w http.ResponseWriter, r *http.Request) {
             sub := appauth.SubjectFromContext(r.Context())
             if sub == "" {
@@ -109,9 +189,9 @@ func main() {
     })
 
     // Optional: ensure Dynamo table(s) when requested
-    if os.Getenv("DYNAMO_AUTO_MIGRATE") == "1" {
+    if cfg.AutoMigrate {
         if dbClient != nil {
-            if err := db.EnsureSingleTable(context.Background(), dbClient, os.Getenv("DYNAMO_TABLE_NAME")); err != nil {
+            if err := db.EnsureSingleTable(context.Background(), dbClient, cfg.TableName); err != nil {
                 log.Printf("dynamo ensure table error: %v", err)
             } else {
                 log.Printf("dynamo ensure table ok")
@@ -119,19 +199,39 @@ func main() {
         }
     }
 
-    addr := ":8080"
-    if v := os.Getenv("PORT"); v != "" {
-        addr = ":" + v
-    }
+    addr := ":" + cfg.Port
 
     server := &http.Server{Addr: addr, Handler: r, ReadTimeout: 15 * time.Second, WriteTimeout: 15 * time.Second}
-    log.Printf("GraphQL server listening on %s/query", addr)
-    if os.Getenv("ENABLE_GRAPHIQL") == "1" {
+    log.Printf("GraphQL server (%s) listening on %s/query", cfg.Env, addr)
+    if cfg.DevToolsEnabled() && cfg.EnableGraphiQL {
         log.Printf("GraphiQL UI enabled at %s/graphiql", addr)
     }
     log.Fatal(server.ListenAndServe())
 }
 
+// mailSender returns the SMTP sender when configured, else one that logs.
+func mailSender(cfg *config.Config) notify.Sender {
+    if cfg.SMTPAddr == "" { return notify.LogSender{} }
+    return &notify.SMTPSender{Addr: cfg.SMTPAddr, From: cfg.SMTPFrom, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword}
+}
+
+// pushClient loads the VAPID keys. In dev a missing pair is replaced by a
+// throwaway one, so subscriptions made before a restart stop working.
+func pushClient(cfg *config.Config) (*push.Client, error) {
+    public, private := cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey
+    if private == "" {
+        if !cfg.DevToolsEnabled() { return nil, nil }
+        var err error
+        if public, private, err = push.GenerateVAPIDKeys(); err != nil { return nil, err }
+        log.Printf("push: VAPID keys not configured; using a temporary pair (public key %s)", public)
+    }
+    v, err := push.ParseVAPID(public, private, cfg.VAPIDSubject)
+    if err != nil { return nil, err }
+    c := push.NewClient(v)
+    if cfg.DevToolsEnabled() { c.HTTP = outbound.NewClient(10*time.Second, false) }
+    return c, nil
+}
+
 // Minimal GraphiQL HTML served in dev
 const graphiqlHTML = `<!DOCTYPE html>
 <html>
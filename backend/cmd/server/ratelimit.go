@@ -0,0 +1,160 @@
+package main
+
+import (
+    "context"
+    "net"
+    "net/http"
+    "net/netip"
+    "strings"
+    "time"
+
+    "github.com/99designs/gqlgen/graphql"
+    "github.com/vektah/gqlparser/v2/ast"
+    "github.com/vektah/gqlparser/v2/gqlerror"
+
+    "chorequest/backend/graph"
+    "chorequest/backend/internal/apperr"
+    appauth "chorequest/backend/internal/auth"
+    "chorequest/backend/internal/ratelimit"
+)
+
+func perMinute(n int) ratelimit.Limit { return ratelimit.Limit{Requests: n, Per: time.Minute} }
+
+// rateKey identifies the caller: the authenticated subject when present,
+// otherwise the client IP (after realIP).
+func rateKey(r *http.Request) string {
+    if sub := appauth.SubjectFromContext(r.Context()); sub != "" {
+        return "sub:" + sub
+    }
+    return "ip:" + clientIP(r)
+}
+
+func clientIP(r *http.Request) string {
+    if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+        return host
+    }
+    return r.RemoteAddr
+}
+
+// realIP replaces RemoteAddr with the client address reported by a trusted
+// proxy. Forwarding headers from anyone else are ignored, since a client could
+// otherwise pick a fresh rate-limit bucket for every request.
+func realIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+    isTrusted := func(a netip.Addr) bool {
+        for _, p := range trusted {
+            if p.Contains(a.Unmap()) { return true }
+        }
+        return false
+    }
+    return func(next http.Handler) http.Handler {
+        if len(trusted) == 0 { return next }
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            peer, err := netip.ParseAddr(clientIP(r))
+            if err != nil || !isTrusted(peer) {
+                next.ServeHTTP(w, r)
+                return
+            }
+            // Walk X-Forwarded-For from the right: the first hop not added by
+            // one of our proxies is the client.
+            var client netip.Addr
+            hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+            for i := len(hops) - 1; i >= 0; i-- {
+                a, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+                if err != nil { break }
+                client = a
+                if !isTrusted(a) { break }
+            }
+            if !client.IsValid() {
+                client, _ = netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+            }
+            if client.IsValid() {
+                r.RemoteAddr = client.Unmap().String()
+            }
+            next.ServeHTTP(w, r)
+        })
+    }
+}
+
+// authFailureLimit throttles failed credential checks per client IP: requests
+// with an API key or bearer token that authn does not accept spend the budget,
+// and once it is empty the IP's credentialed requests get 429 before any
+// check runs. Requests without credentials are not affected.
+func authFailureLimit(store ratelimit.Store, l ratelimit.Limit, authn func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        checked := authn(next)
+        if store == nil || !l.Enabled() { return checked }
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            if r.Header.Get("Authorization") == "" && r.Header.Get("X-API-Key") == "" {
+                checked.ServeHTTP(w, r)
+                return
+            }
+            key := "auth-failure:" + clientIP(r)
+            if wait, err := store.Wait(r.Context(), key, l); err == nil && wait > 0 {
+                w.Header().Set("Retry-After", ratelimit.RetryAfterSeconds(wait))
+                http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+                return
+            }
+            reached := false
+            authn(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                reached = true
+                // An invalid bearer token leaves the caller anonymous.
+                if appauth.SubjectFromContext(r.Context()) == "" { _, _, _ = store.Allow(r.Context(), key, l) }
+                next.ServeHTTP(w, r)
+            })).ServeHTTP(w, r)
+            // authn answered itself, rejecting the API key.
+            if !reached { _, _, _ = store.Allow(r.Context(), key, l) }
+        })
+    }
+}
+
+type rateKeyCtx struct{}
+
+// withRateKey records the caller key for GraphQL extensions further down.
+func withRateKey(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rateKeyCtx{}, rateKey(r))))
+    })
+}
+
+// mutationRateLimit spends a separate budget for each GraphQL mutation, so a
+// client cannot hammer writes within its general request allowance. REST
+// writes draw from the same bucket through allow.
+type mutationRateLimit struct {
+    store ratelimit.Store
+    limit ratelimit.Limit
+}
+
+var _ interface {
+    graphql.HandlerExtension
+    graphql.ResponseInterceptor
+} = mutationRateLimit{}
+
+func (mutationRateLimit) ExtensionName() string { return "MutationRateLimit" }
+
+func (mutationRateLimit) Validate(graphql.ExecutableSchema) error { return nil }
+
+func (m mutationRateLimit) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+    if !m.limit.Enabled() || !graphql.HasOperationContext(ctx) {
+        return next(ctx)
+    }
+    oc := graphql.GetOperationContext(ctx)
+    key, _ := ctx.Value(rateKeyCtx{}).(string)
+    if oc.Operation == nil || oc.Operation.Operation != ast.Mutation || key == "" {
+        return next(ctx)
+    }
+    wait, ok := m.allow(ctx, key)
+    if ok {
+        return next(ctx)
+    }
+    ratelimit.SetRetryAfter(ctx, wait)
+    e := graph.ErrorPresenter(ctx, apperr.New(apperr.RateLimited, "too many requests, retry in %s", ratelimit.RetryAfterSeconds(wait)+"s"))
+    e.Extensions["retryAfter"] = wait.Seconds()
+    return &graphql.Response{Errors: gqlerror.List{e}}
+}
+
+// allow takes a token from the caller's mutation bucket. Store errors fail open.
+func (m mutationRateLimit) allow(ctx context.Context, key string) (time.Duration, bool) {
+    if !m.limit.Enabled() { return 0, true }
+    ok, wait, err := m.store.Allow(ctx, "mutation:"+key, m.limit)
+    return wait, err != nil || ok
+}
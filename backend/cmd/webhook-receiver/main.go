@@ -0,0 +1,44 @@
+// Command webhook-receiver is a local endpoint for testing outbound webhooks.
+// It verifies signatures and prints each delivery. Set FAIL_RATE (0-1) to
+// answer some requests with 500 and watch retries.
+package main
+
+import (
+    "flag"
+    "io"
+    "log"
+    "math/rand/v2"
+    "net/http"
+    "os"
+    "strconv"
+    "time"
+
+    "chorequest/backend/internal/webhook"
+)
+
+func main() {
+    addr := flag.String("addr", ":9090", "listen address")
+    flag.Parse()
+    secret := os.Getenv("WEBHOOK_SECRET")
+    if secret == "" { log.Fatal("WEBHOOK_SECRET must be set to the secret returned by registerWebhook") }
+    failRate, _ := strconv.ParseFloat(os.Getenv("FAIL_RATE"), 64)
+
+    http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+        body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+        if err != nil { http.Error(w, err.Error(), http.StatusBadRequest); return }
+        if err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, 5*time.Minute); err != nil {
+            log.Printf("rejected %s: %v", r.Header.Get(webhook.DeliveryHeader), err)
+            http.Error(w, err.Error(), http.StatusUnauthorized)
+            return
+        }
+        if rand.Float64() < failRate {
+            log.Printf("failing %s on purpose", r.Header.Get(webhook.DeliveryHeader))
+            http.Error(w, "simulated failure", http.StatusInternalServerError)
+            return
+        }
+        log.Printf("%s %s %s", r.Header.Get(webhook.EventHeader), r.Header.Get(webhook.DeliveryHeader), body)
+        w.WriteHeader(http.StatusNoContent)
+    })
+    log.Printf("webhook receiver listening on %s", *addr)
+    log.Fatal(http.ListenAndServe(*addr, nil))
+}
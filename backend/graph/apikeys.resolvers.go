@@ -0,0 +1,35 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    appauth "chorequest/backend/internal/auth"
+    "context"
+)
+
+// CreateAPIKey is the resolver for the createApiKey field.
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, parentID string, name string, scopes []model.APIKeyScope) (*model.CreatedAPIKey, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    if len(scopes) == 0 { return nil, apperr.New(apperr.Validation, "at least one scope is required") }
+    key, prefix, hash, err := appauth.GenerateAPIKey()
+    if err != nil { return nil, err }
+    k, err := r.Repo.CreateAPIKey(ctx, parentID, name, prefix, hash, scopes)
+    if err != nil { return nil, err }
+    return &model.CreatedAPIKey{APIKey: k, Key: key}, nil
+}
+
+// RevokeAPIKey is the resolver for the revokeApiKey field.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, parentID string, id string) (*model.APIKey, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.RevokeAPIKey(ctx, parentID, id)
+}
+
+// APIKeys is the resolver for the apiKeys field.
+func (r *queryResolver) APIKeys(ctx context.Context, parentID string) ([]*model.APIKey, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.ListAPIKeys(ctx, parentID)
+}
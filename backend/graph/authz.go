@@ -0,0 +1,110 @@
+package graph
+
+import (
+    "context"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    appauth "chorequest/backend/internal/auth"
+)
+
+var errForbidden = apperr.New(apperr.Forbidden, "not allowed")
+
+// readScopes lets any API key read its own household.
+var readScopes = model.AllAPIKeyScope
+
+// authorize applies API key restrictions: the key must grant one of scopes and
+// belong to the household of parentID. Callers without an API key (JWT or
+// anonymous) are not restricted here. Passing no scopes denies all API keys.
+func (r *Resolver) authorize(ctx context.Context, parentID string, scopes ...model.APIKeyScope) error {
+    granted, ok := appauth.ScopesFromContext(ctx)
+    if !ok {
+        return nil
+    }
+    if appauth.SubjectFromContext(ctx) != parentID {
+        return errForbidden
+    }
+    for _, g := range granted {
+        for _, s := range scopes {
+            if g == string(s) {
+                return nil
+            }
+        }
+    }
+    return errForbidden
+}
+
+// authorizeChild is authorize for operations addressed by child ID.
+func (r *Resolver) authorizeChild(ctx context.Context, childID string, scopes ...model.APIKeyScope) error {
+    if _, ok := appauth.ScopesFromContext(ctx); !ok {
+        return nil
+    }
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return err }
+    return r.authorize(ctx, ch.ParentID, scopes...)
+}
+
+// requireSelf restricts account-security operations to the signed-in parent
+// themselves; API keys and anonymous callers are always refused.
+func requireSelf(ctx context.Context, parentID string) error {
+    if _, ok := appauth.ScopesFromContext(ctx); ok {
+        return errForbidden
+    }
+    if appauth.SubjectFromContext(ctx) != parentID || appauth.RoleFromContext(ctx) != string(model.RoleParent) {
+        return errForbidden
+    }
+    return nil
+}
+
+// authorizeViewer checks that the caller may watch the household of parentID:
+// its parent or one of its API keys. Anonymous callers are refused.
+func (r *Resolver) authorizeViewer(ctx context.Context, parentID string) error {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return err }
+    sub := appauth.SubjectFromContext(ctx)
+    if sub == "" { return errForbidden }
+    if appauth.RoleFromContext(ctx) == string(model.RoleChild) || sub != parentID { return errForbidden }
+    return nil
+}
+
+// authorizeSignedIn admits only a signed-in child or parent; API keys and
+// anonymous callers are refused.
+func (r *Resolver) authorizeSignedIn(ctx context.Context) error {
+    if _, ok := appauth.ScopesFromContext(ctx); ok { return errForbidden }
+    if appauth.SubjectFromContext(ctx) == "" || !model.Role(appauth.RoleFromContext(ctx)).IsValid() { return errForbidden }
+    return nil
+}
+
+// authorizeChildViewer lets a child watch only themselves and a parent only
+// their own children; anonymous callers are refused.
+func (r *Resolver) authorizeChildViewer(ctx context.Context, childID string) error {
+    sub := appauth.SubjectFromContext(ctx)
+    if sub == "" { return errForbidden }
+    if appauth.RoleFromContext(ctx) == string(model.RoleChild) {
+        if sub != childID { return errForbidden }
+        return nil
+    }
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return err }
+    return r.authorizeViewer(ctx, ch.ParentID)
+}
+
+// authorizeChildParent restricts an operation to the parent of childID;
+// children and API keys are refused.
+func (r *Resolver) authorizeChildParent(ctx context.Context, childID string) error {
+    if err := r.authorizeChild(ctx, childID); err != nil { return err }
+    if appauth.RoleFromContext(ctx) == string(model.RoleChild) { return errForbidden }
+    return r.authorizeChildViewer(ctx, childID)
+}
+
+// authorizeParent restricts decisions such as approving money matters to the
+// signed-in parent of the household; API keys, child logins and anonymous
+// callers are refused.
+func (r *Resolver) authorizeParent(ctx context.Context, parentID string) error {
+    return requireSelf(ctx, parentID)
+}
+
+// isHouseholdParent reports whether the caller is the signed-in parent of
+// parentID, the only caller exempt from the guardrails parents set for children.
+func isHouseholdParent(ctx context.Context, parentID string) bool {
+    return requireSelf(ctx, parentID) == nil
+}
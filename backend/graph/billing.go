@@ -0,0 +1,35 @@
+package graph
+
+import (
+    "context"
+    "fmt"
+
+    stripe "github.com/stripe/stripe-go/v76"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/billing"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+func subscriptionStatus(b *repopkg.Billing) *model.SubscriptionStatus {
+    st := &model.SubscriptionStatus{
+        Active: b.Active(), CurrentPeriodEnd: b.CurrentPeriodEnd,
+        CancelAtPeriodEnd: b.CancelAtPeriodEnd, PaymentFailedAt: b.PaymentFailedAt,
+    }
+    if b.Status != "" { st.Status = &b.Status }
+    return st
+}
+
+// stripeCustomer returns the parent's Stripe customer, creating and linking one
+// on first use. The idempotency key makes concurrent first calls share it.
+func (r *Resolver) stripeCustomer(ctx context.Context, parentID string, b *repopkg.Billing) (string, error) {
+    if b.CustomerID != "" { return b.CustomerID, nil }
+    params := &stripe.CustomerParams{Metadata: map[string]string{billing.ParentMetadataKey: parentID}}
+    params.Context = ctx
+    params.SetIdempotencyKey("customer-" + parentID)
+    c, err := r.Stripe.Customers.New(params)
+    if err != nil { return "", fmt.Errorf("stripe: %w", err) }
+    if err := r.Repo.LinkCustomer(ctx, parentID, c.ID, ""); err != nil { return "", err }
+    b.CustomerID = c.ID
+    return c.ID, nil
+}
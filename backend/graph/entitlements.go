@@ -0,0 +1,23 @@
+package graph
+
+import (
+    "context"
+
+    "chorequest/backend/internal/entitlements"
+)
+
+// plans enforces the household plan limits configured for this server.
+func (r *Resolver) plans() *entitlements.Checker { return entitlements.New(r.Repo, r.Config) }
+
+// writeChild refuses changes to a child that is read-only on the household's plan.
+func (r *Resolver) writeChild(ctx context.Context, childID string) error {
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return err }
+    return r.plans().WriteChild(ctx, ch.ParentID, childID)
+}
+
+// limit is nil for an unlimited (0) cap.
+func limit(n int) *int {
+    if n == 0 { return nil }
+    return &n
+}
@@ -0,0 +1,25 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "context"
+)
+
+// Entitlements is the resolver for the entitlements field.
+func (r *queryResolver) Entitlements(ctx context.Context, parentID string) (*model.Entitlements, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    plans := r.plans()
+    h, err := plans.For(ctx, parentID)
+    if err != nil { return nil, err }
+    u, err := plans.Usage(ctx, h)
+    if err != nil { return nil, err }
+    return &model.Entitlements{
+        ParentID: parentID, Plan: h.Plan,
+        MaxChildren: limit(h.Limits.MaxChildren), MaxActiveQuests: limit(h.Limits.MaxActiveQuests), RecurringSchedules: h.Limits.RecurringSchedules,
+        Children: u.Children, Quests: u.Quests, ReadOnlyChildIds: u.ReadOnlyChildren, ReadOnlyQuestIds: u.ReadOnlyQuests,
+    }, nil
+}
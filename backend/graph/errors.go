@@ -0,0 +1,43 @@
+package graph
+
+import (
+    "context"
+    "errors"
+    "log"
+
+    "github.com/99designs/gqlgen/graphql"
+    "github.com/vektah/gqlparser/v2/gqlerror"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// ErrorPresenter maps domain errors to GraphQL errors with extensions.code.
+// Errors that are not *apperr.Error are logged and reported as INTERNAL so
+// storage and third-party messages never reach clients.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+    gerr := graphql.DefaultErrorPresenter(ctx, err)
+    var ae *apperr.Error
+    switch {
+    case errors.As(err, &ae):
+        gerr.Message = ae.Message
+        setCode(gerr, ae.Code)
+        if ae.Field != "" {
+            gerr.Extensions["field"] = ae.Field
+        }
+    case gerr.Err == nil:
+        // Already a GraphQL error (parsing, validation, argument coercion).
+        return gerr
+    default:
+        log.Printf("graphql: %v", err)
+        gerr.Message = "internal error"
+        setCode(gerr, apperr.Internal)
+    }
+    return gerr
+}
+
+func setCode(gerr *gqlerror.Error, code apperr.Code) {
+    if gerr.Extensions == nil {
+        gerr.Extensions = map[string]any{}
+    }
+    gerr.Extensions["code"] = string(code)
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "log"
+    "strings"
+    "time"
+
+    "github.com/99designs/gqlgen/graphql"
+    "github.com/vektah/gqlparser/v2/ast"
+    "github.com/vektah/gqlparser/v2/gqlerror"
+
+    "chorequest/backend/internal/apperr"
+    appauth "chorequest/backend/internal/auth"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// IdempotencyHeader carries a client-chosen key that makes a mutation safe to retry.
+const IdempotencyHeader = "Idempotency-Key"
+
+const (
+    // idempotencyLease bounds an in-progress claim, well above the server's
+    // write timeout; a retry after it expires runs the request again.
+    idempotencyLease  = time.Minute
+    idempotencyTTL    = 24 * time.Hour
+    maxIdempotencyKey = 255
+)
+
+// Idempotency replays the stored result of a mutation when the same caller
+// repeats it with the same Idempotency-Key. Only successes and validation
+// failures are stored; any other error may be transient, so the key is
+// released and a retry executes again. Keys require a signed-in caller or API key.
+type Idempotency struct {
+    Store repopkg.Repo
+}
+
+var _ interface {
+    graphql.HandlerExtension
+    graphql.ResponseInterceptor
+} = Idempotency{}
+
+func (Idempotency) ExtensionName() string { return "Idempotency" }
+
+func (Idempotency) Validate(graphql.ExecutableSchema) error { return nil }
+
+func (i Idempotency) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+    if !graphql.HasOperationContext(ctx) {
+        return next(ctx)
+    }
+    oc := graphql.GetOperationContext(ctx)
+    key := strings.TrimSpace(oc.Headers.Get(IdempotencyHeader))
+    if i.Store == nil || key == "" || oc.Operation == nil || oc.Operation.Operation != ast.Mutation {
+        return next(ctx)
+    }
+    var resp *graphql.Response
+    body, replayed, err := i.Run(ctx, key, fingerprint(oc), func() ([]byte, bool) {
+        resp = next(ctx)
+        if !replayable(resp) { return nil, false }
+        b, err := json.Marshal(resp)
+        if err != nil {
+            log.Printf("idempotency encode %s: %v", key, err)
+            return nil, false
+        }
+        return b, true
+    })
+    if err != nil {
+        return errorResponse(ctx, err)
+    }
+    if !replayed {
+        return resp
+    }
+    var stored graphql.Response
+    if err := json.Unmarshal(body, &stored); err != nil {
+        return errorResponse(ctx, err)
+    }
+    if stored.Extensions == nil { stored.Extensions = map[string]any{} }
+    stored.Extensions["idempotentReplay"] = true
+    return &stored
+}
+
+// Run executes run once per caller and key and stores the body it returns,
+// unless run reports the result is not worth keeping. A repeat with the same
+// fingerprint gets the stored body back with replayed set. It is shared by
+// GraphQL mutations and REST writes.
+func (i Idempotency) Run(ctx context.Context, key, fp string, run func() (body []byte, store bool)) (body []byte, replayed bool, err error) {
+    if i.Store == nil {
+        body, _ = run()
+        return body, false, nil
+    }
+    if len(key) > maxIdempotencyKey {
+        return nil, false, apperr.Invalid(IdempotencyHeader, "must be at most %d characters", maxIdempotencyKey)
+    }
+    // Keys are scoped per caller so one user can never replay another's result.
+    sub := appauth.SubjectFromContext(ctx)
+    if sub == "" {
+        return nil, false, apperr.New(apperr.Forbidden, "%s requires a signed-in caller", IdempotencyHeader)
+    }
+    scoped := sub + "#" + key
+
+    rec, claimed, err := i.Store.ClaimIdempotencyKey(ctx, scoped, fp, idempotencyLease)
+    if err != nil {
+        return nil, false, err
+    }
+    if !claimed {
+        if rec.Fingerprint != fp {
+            return nil, false, apperr.New(apperr.Conflict, "idempotency key was already used for a different request")
+        }
+        if len(rec.Response) == 0 {
+            return nil, false, apperr.New(apperr.Conflict, "a request with this idempotency key is still in progress")
+        }
+        return rec.Response, true, nil
+    }
+
+    body, keep := run()
+    // Store with a detached context: the client may already have gone away,
+    // which is exactly the case a later retry needs the record for.
+    sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+    defer cancel()
+    if !keep {
+        if err := i.Store.ReleaseIdempotencyKey(sctx, scoped, rec.Claim); err != nil {
+            log.Printf("idempotency release %s: %v", key, err)
+        }
+        return body, false, nil
+    }
+    if err := i.Store.CompleteIdempotencyKey(sctx, scoped, rec.Claim, body, idempotencyTTL); err != nil {
+        log.Printf("idempotency store %s: %v", key, err)
+    }
+    return body, false, nil
+}
+
+func fingerprint(oc *graphql.OperationContext) string {
+    vars, _ := json.Marshal(oc.Variables)
+    sum := sha256.Sum256([]byte(oc.OperationName + "\x00" + oc.RawQuery + "\x00" + string(vars)))
+    return hex.EncodeToString(sum[:])
+}
+
+// replayable reports whether resp would be the same if the request ran again:
+// it succeeded, or failed validation only.
+func replayable(resp *graphql.Response) bool {
+    if resp == nil { return false }
+    for _, e := range resp.Errors {
+        if e.Extensions == nil || e.Extensions["code"] != string(apperr.Validation) {
+            return false
+        }
+    }
+    return true
+}
+
+func errorResponse(ctx context.Context, err error) *graphql.Response {
+    return &graphql.Response{Errors: gqlerror.List{ErrorPresenter(ctx, err)}}
+}
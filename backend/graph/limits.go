@@ -0,0 +1,161 @@
+package graph
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strings"
+
+    "github.com/99designs/gqlgen/graphql"
+    "github.com/vektah/gqlparser/v2/ast"
+    "github.com/vektah/gqlparser/v2/formatter"
+    "github.com/vektah/gqlparser/v2/gqlerror"
+    "github.com/vektah/gqlparser/v2/parser"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// DepthLimit rejects operations whose selection sets nest deeper than Max.
+// Introspection fields are not counted.
+type DepthLimit struct {
+    Max int
+}
+
+var _ interface {
+    graphql.HandlerExtension
+    graphql.OperationContextMutator
+} = DepthLimit{}
+
+func (DepthLimit) ExtensionName() string { return "DepthLimit" }
+
+func (DepthLimit) Validate(graphql.ExecutableSchema) error { return nil }
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, oc *graphql.OperationContext) *gqlerror.Error {
+    if d.Max <= 0 || oc.Operation == nil {
+        return nil
+    }
+    if depth := selectionDepth(oc.Operation.SelectionSet, map[string]bool{}); depth > d.Max {
+        err := gqlerror.Errorf("operation depth %d exceeds limit %d", depth, d.Max)
+        err.Extensions = map[string]any{"code": string(apperr.Validation)}
+        return err
+    }
+    return nil
+}
+
+// selectionDepth follows fragments once per path to stay finite on cycles
+// (which validation rejects anyway).
+func selectionDepth(set ast.SelectionSet, seen map[string]bool) int {
+    max := 0
+    for _, sel := range set {
+        var d int
+        switch s := sel.(type) {
+        case *ast.Field:
+            if strings.HasPrefix(s.Name, "__") {
+                continue
+            }
+            d = 1 + selectionDepth(s.SelectionSet, seen)
+        case *ast.InlineFragment:
+            d = selectionDepth(s.SelectionSet, seen)
+        case *ast.FragmentSpread:
+            if s.Definition == nil || seen[s.Name] {
+                continue
+            }
+            seen[s.Name] = true
+            d = selectionDepth(s.Definition.SelectionSet, seen)
+            delete(seen, s.Name)
+        }
+        if d > max {
+            max = d
+        }
+    }
+    return max
+}
+
+// Allowlist holds the operations the web app is known to send, as produced by
+// graphql-codegen's persisted documents output ({"<sha256>": "<document>"}).
+// It doubles as the automatic persisted query cache: allowlisted hashes always
+// resolve, and in enforcing mode nothing else can be registered or executed.
+type Allowlist struct {
+    Enforce   bool
+    byHash    map[string]string
+    canonical map[string]bool
+    fallback  graphql.Cache[string]
+}
+
+var _ interface {
+    graphql.HandlerExtension
+    graphql.OperationContextMutator
+    graphql.Cache[string]
+} = (*Allowlist)(nil)
+
+// LoadAllowlist reads a persisted documents file. fallback caches ad-hoc APQ
+// registrations when not enforcing; it may be nil.
+func LoadAllowlist(path string, enforce bool, fallback graphql.Cache[string]) (*Allowlist, error) {
+    a := &Allowlist{Enforce: enforce, byHash: map[string]string{}, canonical: map[string]bool{}, fallback: fallback}
+    if path == "" {
+        return a, nil
+    }
+    raw, err := os.ReadFile(path)
+    if err != nil { return nil, fmt.Errorf("read allowlist: %w", err) }
+    docs := map[string]string{}
+    if err := json.Unmarshal(raw, &docs); err != nil { return nil, fmt.Errorf("parse allowlist: %w", err) }
+    for hash, query := range docs {
+        doc, err := parser.ParseQuery(&ast.Source{Input: query})
+        if err != nil { return nil, fmt.Errorf("allowlist entry %s: %w", hash, err) }
+        a.byHash[hash] = query
+        // Clients may hash a differently printed document, so also index by sha256 of the query text.
+        a.byHash[sha256Hex(query)] = query
+        a.canonical[canonicalize(doc)] = true
+    }
+    return a, nil
+}
+
+// Len reports the number of allowlisted documents.
+func (a *Allowlist) Len() int { return len(a.canonical) }
+
+func (*Allowlist) ExtensionName() string { return "Allowlist" }
+
+func (*Allowlist) Validate(graphql.ExecutableSchema) error { return nil }
+
+func (a *Allowlist) MutateOperationContext(ctx context.Context, oc *graphql.OperationContext) *gqlerror.Error {
+    if !a.Enforce || oc.Doc == nil || a.canonical[canonicalize(oc.Doc)] {
+        return nil
+    }
+    err := gqlerror.Errorf("operation is not in the allowlist")
+    err.Extensions = map[string]any{"code": string(apperr.Forbidden)}
+    return err
+}
+
+func (a *Allowlist) Get(ctx context.Context, key string) (string, bool) {
+    if q, ok := a.byHash[key]; ok {
+        return q, true
+    }
+    if a.Enforce || a.fallback == nil {
+        return "", false
+    }
+    return a.fallback.Get(ctx, key)
+}
+
+func (a *Allowlist) Add(ctx context.Context, key, value string) {
+    if a.Enforce || a.fallback == nil {
+        return
+    }
+    a.fallback.Add(ctx, key, value)
+}
+
+// canonicalize prints doc in gqlparser's normal form so whitespace and comment
+// differences between client and allowlist do not matter.
+func canonicalize(doc *ast.QueryDocument) string {
+    var buf bytes.Buffer
+    formatter.NewFormatter(&buf, formatter.WithoutDescription()).FormatQueryDocument(doc)
+    return sha256Hex(buf.String())
+}
+
+func sha256Hex(s string) string {
+    sum := sha256.Sum256([]byte(s))
+    return hex.EncodeToString(sum[:])
+}
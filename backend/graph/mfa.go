@@ -0,0 +1,39 @@
+package graph
+
+import (
+    "context"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    appauth "chorequest/backend/internal/auth"
+)
+
+const (
+    totpIssuer        = "ChoreQuest"
+    recoveryCodeCount = 10
+)
+
+var errInvalidCode = apperr.New(apperr.Validation, "invalid verification code")
+
+// newRecoveryCodes returns plaintext codes for the parent and their hashes for storage.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+    codes, err = appauth.GenerateRecoveryCodes(recoveryCodeCount)
+    if err != nil { return nil, nil, err }
+    for _, c := range codes {
+        hashes = append(hashes, appauth.HashRecoveryCode(c))
+    }
+    return codes, hashes, nil
+}
+
+// verifyEnabledMFA checks code against an enabled enrollment for parentID.
+func (r *Resolver) verifyEnabledMFA(ctx context.Context, parentID, code string) error {
+    st, err := r.Repo.GetMFA(ctx, parentID)
+    if err != nil { return err }
+    if st.EnabledAt == nil { return apperr.New(apperr.Validation, "two-factor authentication is not enabled") }
+    if !appauth.VerifySecondFactor(ctx, r.Repo, parentID, st.Secret, code) { return errInvalidCode }
+    return nil
+}
+
+func mfaStatus(enabledAt *string, remaining int) *model.MfaStatus {
+    return &model.MfaStatus{Enabled: enabledAt != nil, EnabledAt: enabledAt, RecoveryCodesRemaining: remaining}
+}
@@ -0,0 +1,62 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    appauth "chorequest/backend/internal/auth"
+    "context"
+    "time"
+)
+
+// EnrollTotp is the resolver for the enrollTotp field.
+func (r *mutationResolver) EnrollTotp(ctx context.Context, parentID string) (*model.TotpEnrollment, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    secret, err := appauth.GenerateTOTPSecret()
+    if err != nil { return nil, err }
+    if err := r.Repo.SetPendingTOTP(ctx, parentID, secret); err != nil { return nil, err }
+    return &model.TotpEnrollment{Secret: secret, ProvisioningURI: appauth.TOTPProvisioningURI(totpIssuer, parentID, secret)}, nil
+}
+
+// VerifyTotp is the resolver for the verifyTotp field.
+func (r *mutationResolver) VerifyTotp(ctx context.Context, parentID string, code string) ([]string, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    st, err := r.Repo.GetMFA(ctx, parentID)
+    if err != nil { return nil, err }
+    if st.Secret == "" || st.EnabledAt != nil { return nil, errInvalidCode }
+    step, ok := appauth.ValidateTOTP(st.Secret, code, time.Now())
+    if !ok { return nil, errInvalidCode }
+    if err := r.Repo.UseTOTPStep(ctx, parentID, step); err != nil { return nil, errInvalidCode }
+    codes, hashes, err := newRecoveryCodes()
+    if err != nil { return nil, err }
+    if err := r.Repo.EnableMFA(ctx, parentID, hashes); err != nil { return nil, err }
+    return codes, nil
+}
+
+// RegenerateRecoveryCodes is the resolver for the regenerateRecoveryCodes field.
+func (r *mutationResolver) RegenerateRecoveryCodes(ctx context.Context, parentID string, code string) ([]string, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    if err := r.verifyEnabledMFA(ctx, parentID, code); err != nil { return nil, err }
+    codes, hashes, err := newRecoveryCodes()
+    if err != nil { return nil, err }
+    if err := r.Repo.ReplaceRecoveryCodes(ctx, parentID, hashes); err != nil { return nil, err }
+    return codes, nil
+}
+
+// DisableTotp is the resolver for the disableTotp field.
+func (r *mutationResolver) DisableTotp(ctx context.Context, parentID string, code string) (*model.MfaStatus, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    if err := r.verifyEnabledMFA(ctx, parentID, code); err != nil { return nil, err }
+    if err := r.Repo.DisableMFA(ctx, parentID); err != nil { return nil, err }
+    return mfaStatus(nil, 0), nil
+}
+
+// MfaStatus is the resolver for the mfaStatus field.
+func (r *queryResolver) MfaStatus(ctx context.Context, parentID string) (*model.MfaStatus, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return nil, err }
+    st, err := r.Repo.GetMFA(ctx, parentID)
+    if err != nil { return nil, err }
+    return mfaStatus(st.EnabledAt, len(st.RecoveryHashes)), nil
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// AgeOn returns the whole years between birthdate (YYYY-MM-DD) and now, or
+// false if birthdate is missing or malformed.
+func AgeOn(birthdate *string, now time.Time) (int, bool) {
+    if birthdate == nil { return 0, false }
+    b, err := time.Parse(time.DateOnly, *birthdate)
+    if err != nil { return 0, false }
+    now = now.UTC()
+    age := now.Year() - b.Year()
+    if now.Month() < b.Month() || now.Month() == b.Month() && now.Day() < b.Day() { age-- }
+    return max(age, 0), true
+}
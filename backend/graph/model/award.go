@@ -0,0 +1,15 @@
+package model
+
+// EarnedXp and EarnedGold return what completing a paid. Assignments completed
+// before awards were recorded, or still open, fall back to the quest's values.
+func (a *Assignment) EarnedXp() int {
+    if a.XpAwarded != nil { return *a.XpAwarded }
+    if a.Quest == nil { return 0 }
+    return a.Quest.Xp
+}
+
+func (a *Assignment) EarnedGold() int {
+    if a.GoldAwarded != nil { return *a.GoldAwarded }
+    if a.Quest == nil { return 0 }
+    return a.Quest.Gold
+}
@@ -0,0 +1,23 @@
+package model
+
+// Percent returns the share of xp and gold paid for quests of difficulty d;
+// nil multipliers pay everything.
+func (m *DifficultyMultipliers) Percent(d Difficulty) int {
+    if m == nil { return 100 }
+    switch d {
+    case DifficultyEasy:
+        return m.Easy
+    case DifficultyMedium:
+        return m.Medium
+    case DifficultyHard:
+        return m.Hard
+    }
+    return 100
+}
+
+// Pay returns the xp and gold completing q pays under m.
+func (m *DifficultyMultipliers) Pay(q *Quest) (xp, gold int) {
+    pct := 100
+    if q.Difficulty != nil { pct = m.Percent(*q.Difficulty) }
+    return q.Xp * pct / 100, q.Gold * pct / 100
+}
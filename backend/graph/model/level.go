@@ -0,0 +1,10 @@
+package model
+
+// XPPerLevel is the XP needed to advance one level.
+const XPPerLevel = 100
+
+// LevelForXP returns the level reached with xp, starting at 1.
+func LevelForXP(xp int) int {
+    if xp < 0 { return 1 }
+    return xp/XPPerLevel + 1
+}
@@ -9,6 +9,17 @@ import (
 	"strconv"
 )
 
+type APIKey struct {
+	ID         string        `json:"id"`
+	ParentID   string        `json:"parentId"`
+	Name       string        `json:"name"`
+	Prefix     string        `json:"prefix"`
+	Scopes     []APIKeyScope `json:"scopes"`
+	CreatedAt  string        `json:"createdAt"`
+	LastUsedAt *string       `json:"lastUsedAt,omitempty"`
+	RevokedAt  *string       `json:"revokedAt,omitempty"`
+}
+
 type Assignment struct {
 	ID          string  `json:"id"`
 	Quest       *Quest  `json:"quest"`
@@ -16,6 +27,8 @@ type Assignment struct {
 	Status      string  `json:"status"`
 	CreatedAt   string  `json:"createdAt"`
 	CompletedAt *string `json:"completedAt,omitempty"`
+	XpAwarded   *int    `json:"xpAwarded,omitempty"`
+	GoldAwarded *int    `json:"goldAwarded,omitempty"`
 }
 
 type AvatarItem struct {
@@ -24,28 +37,127 @@ type AvatarItem struct {
 	PriceGold int    `json:"priceGold"`
 }
 
+type CategoryCount struct {
+	Category   *QuestCategory `json:"category,omitempty"`
+	Completed  int            `json:"completed"`
+	XpEarned   int            `json:"xpEarned"`
+	GoldEarned int            `json:"goldEarned"`
+}
+
 type Child struct {
-	ID       string `json:"id"`
-	ParentID string `json:"parentId"`
-	Name     string `json:"name"`
-	Xp       int    `json:"xp"`
-	Gold     int    `json:"gold"`
+	ID        string  `json:"id"`
+	ParentID  string  `json:"parentId"`
+	Name      string  `json:"name"`
+	Xp        int     `json:"xp"`
+	Gold      int     `json:"gold"`
+	Level     int     `json:"level"`
+	CreatedAt *string `json:"createdAt,omitempty"`
+	Birthdate *string `json:"birthdate,omitempty"`
+	Age       *int    `json:"age,omitempty"`
+}
+
+type ChildWeeklyReport struct {
+	Child               *Child           `json:"child"`
+	QuestsCompleted     int              `json:"questsCompleted"`
+	XpEarned            int              `json:"xpEarned"`
+	GoldEarned          int              `json:"goldEarned"`
+	GoldSpent           int              `json:"goldSpent"`
+	Completed           []*Assignment    `json:"completed"`
+	Purchases           []*Purchase      `json:"purchases"`
+	OverdueChores       []*Assignment    `json:"overdueChores"`
+	StreakDays          int              `json:"streakDays"`
+	LongestStreakDays   int              `json:"longestStreakDays"`
+	CompletedByCategory []*CategoryCount `json:"completedByCategory"`
+}
+
+type CreatedAPIKey struct {
+	APIKey *APIKey `json:"apiKey"`
+	Key    string  `json:"key"`
+}
+
+type DifficultyMultipliers struct {
+	Easy   int `json:"easy"`
+	Medium int `json:"medium"`
+	Hard   int `json:"hard"`
+}
+
+type DifficultyMultipliersInput struct {
+	Easy   int `json:"easy"`
+	Medium int `json:"medium"`
+	Hard   int `json:"hard"`
+}
+
+type Entitlements struct {
+	ParentID           string   `json:"parentId"`
+	Plan               Plan     `json:"plan"`
+	MaxChildren        *int     `json:"maxChildren,omitempty"`
+	MaxActiveQuests    *int     `json:"maxActiveQuests,omitempty"`
+	RecurringSchedules bool     `json:"recurringSchedules"`
+	Children           int      `json:"children"`
+	Quests             int      `json:"quests"`
+	ReadOnlyChildIds   []string `json:"readOnlyChildIds"`
+	ReadOnlyQuestIds   []string `json:"readOnlyQuestIds"`
+}
+
+type ExchangeRate struct {
+	Currency   string `json:"currency"`
+	Gold       int    `json:"gold"`
+	MinorUnits int    `json:"minorUnits"`
+}
+
+type ExchangeRateInput struct {
+	Currency   string `json:"currency"`
+	Gold       int    `json:"gold"`
+	MinorUnits int    `json:"minorUnits"`
+}
+
+type GoldTransfer struct {
+	ID          string         `json:"id"`
+	ParentID    string         `json:"parentId"`
+	FromChildID string         `json:"fromChildId"`
+	ToChildID   string         `json:"toChildId"`
+	Gold        int            `json:"gold"`
+	Note        *string        `json:"note,omitempty"`
+	Status      TransferStatus `json:"status"`
+	CreatedAt   string         `json:"createdAt"`
+	DecidedAt   *string        `json:"decidedAt,omitempty"`
+}
+
+type MfaStatus struct {
+	Enabled                bool    `json:"enabled"`
+	EnabledAt              *string `json:"enabledAt,omitempty"`
+	RecoveryCodesRemaining int     `json:"recoveryCodesRemaining"`
 }
 
 type Mutation struct {
 }
 
 type NewChild struct {
-	ParentID string `json:"parentId"`
-	Name     string `json:"name"`
+	ParentID  string  `json:"parentId"`
+	Name      string  `json:"name"`
+	Birthdate *string `json:"birthdate,omitempty"`
 }
 
 type NewQuest struct {
-	ParentID    string  `json:"parentId"`
-	Title       string  `json:"title"`
-	Description *string `json:"description,omitempty"`
-	Xp          int     `json:"xp"`
-	Gold        int     `json:"gold"`
+	ParentID    string         `json:"parentId"`
+	Title       string         `json:"title"`
+	Description *string        `json:"description,omitempty"`
+	Xp          int            `json:"xp"`
+	Gold        int            `json:"gold"`
+	Category    *QuestCategory `json:"category,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	Difficulty  *Difficulty    `json:"difficulty,omitempty"`
+}
+
+type NewQuestTemplate struct {
+	ParentID    string         `json:"parentId"`
+	Title       string         `json:"title"`
+	Description *string        `json:"description,omitempty"`
+	Xp          int            `json:"xp"`
+	Gold        int            `json:"gold"`
+	MinAge      int            `json:"minAge"`
+	MaxAge      int            `json:"maxAge"`
+	Category    *QuestCategory `json:"category,omitempty"`
 }
 
 type NewReward struct {
@@ -54,16 +166,143 @@ type NewReward struct {
 	XpThreshold int    `json:"xpThreshold"`
 }
 
+type NewSavingsGoal struct {
+	ChildID    string `json:"childId"`
+	Name       string `json:"name"`
+	TargetGold int    `json:"targetGold"`
+}
+
+type NotificationPreferences struct {
+	ParentID       string  `json:"parentId"`
+	Email          *string `json:"email,omitempty"`
+	ChoreSubmitted bool    `json:"choreSubmitted"`
+	ItemPurchased  bool    `json:"itemPurchased"`
+	WeeklyDigest   bool    `json:"weeklyDigest"`
+}
+
+type NotificationPreferencesInput struct {
+	ParentID       string  `json:"parentId"`
+	Email          *string `json:"email,omitempty"`
+	ChoreSubmitted bool    `json:"choreSubmitted"`
+	ItemPurchased  bool    `json:"itemPurchased"`
+	WeeklyDigest   bool    `json:"weeklyDigest"`
+}
+
+type Payout struct {
+	ID          string       `json:"id"`
+	ParentID    string       `json:"parentId"`
+	ChildID     string       `json:"childId"`
+	Gold        int          `json:"gold"`
+	AmountMinor int          `json:"amountMinor"`
+	Currency    string       `json:"currency"`
+	Status      PayoutStatus `json:"status"`
+	RequestedAt string       `json:"requestedAt"`
+	DecidedAt   *string      `json:"decidedAt,omitempty"`
+	PaidAt      *string      `json:"paidAt,omitempty"`
+}
+
+type Purchase struct {
+	ID          string `json:"id"`
+	ChildID     string `json:"childId"`
+	ItemName    string `json:"itemName"`
+	PriceGold   int    `json:"priceGold"`
+	PurchasedAt string `json:"purchasedAt"`
+}
+
+type PurchaseRequest struct {
+	ID          string                `json:"id"`
+	ParentID    string                `json:"parentId"`
+	ChildID     string                `json:"childId"`
+	ItemName    string                `json:"itemName"`
+	PriceGold   int                   `json:"priceGold"`
+	Reason      PurchaseHoldReason    `json:"reason"`
+	Status      PurchaseRequestStatus `json:"status"`
+	RequestedAt string                `json:"requestedAt"`
+	DecidedAt   *string               `json:"decidedAt,omitempty"`
+}
+
+type PushSubscription struct {
+	ID        string `json:"id"`
+	ChildID   string `json:"childId"`
+	Endpoint  string `json:"endpoint"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type PushSubscriptionInput struct {
+	ChildID  string `json:"childId"`
+	Endpoint string `json:"endpoint"`
+	P256dh   string `json:"p256dh"`
+	Auth     string `json:"auth"`
+}
+
 type Query struct {
 }
 
 type Quest struct {
-	ID          string  `json:"id"`
-	ParentID    string  `json:"parentId"`
-	Title       string  `json:"title"`
-	Description *string `json:"description,omitempty"`
-	Xp          int     `json:"xp"`
-	Gold        int     `json:"gold"`
+	ID          string         `json:"id"`
+	ParentID    string         `json:"parentId"`
+	Title       string         `json:"title"`
+	Description *string        `json:"description,omitempty"`
+	Xp          int            `json:"xp"`
+	Gold        int            `json:"gold"`
+	CreatedAt   *string        `json:"createdAt,omitempty"`
+	Category    *QuestCategory `json:"category,omitempty"`
+	Tags        []string       `json:"tags"`
+	Difficulty  *Difficulty    `json:"difficulty,omitempty"`
+}
+
+type QuestDetailsInput struct {
+	Category   *QuestCategory `json:"category,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Difficulty *Difficulty    `json:"difficulty,omitempty"`
+}
+
+type QuestSchedule struct {
+	ID               string     `json:"id"`
+	ParentID         string     `json:"parentId"`
+	QuestID          string     `json:"questId"`
+	ChildID          string     `json:"childId"`
+	Recurrence       Recurrence `json:"recurrence"`
+	NextRunAt        string     `json:"nextRunAt"`
+	LastAssignmentID *string    `json:"lastAssignmentId,omitempty"`
+	Paused           bool       `json:"paused"`
+	CreatedAt        string     `json:"createdAt"`
+}
+
+type QuestSuggestion struct {
+	Template *QuestTemplate `json:"template"`
+	Score    float64        `json:"score"`
+	Reasons  []string       `json:"reasons"`
+	QuestID  *string        `json:"questId,omitempty"`
+}
+
+type QuestTemplate struct {
+	ID          string         `json:"id"`
+	ParentID    *string        `json:"parentId,omitempty"`
+	Title       string         `json:"title"`
+	Description *string        `json:"description,omitempty"`
+	Xp          int            `json:"xp"`
+	Gold        int            `json:"gold"`
+	MinAge      int            `json:"minAge"`
+	MaxAge      int            `json:"maxAge"`
+	Category    *QuestCategory `json:"category,omitempty"`
+}
+
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"timeZone"`
+}
+
+type QuietHoursInput struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	TimeZone string `json:"timeZone"`
+}
+
+type RegisteredWebhook struct {
+	Webhook *Webhook `json:"webhook"`
+	Secret  string   `json:"secret"`
 }
 
 type Reward struct {
@@ -73,9 +312,62 @@ type Reward struct {
 	XpThreshold int    `json:"xpThreshold"`
 }
 
+type SavingsGoal struct {
+	ID                   string       `json:"id"`
+	ParentID             string       `json:"parentId"`
+	ChildID              string       `json:"childId"`
+	Name                 string       `json:"name"`
+	TargetGold           int          `json:"targetGold"`
+	SavedGold            int          `json:"savedGold"`
+	BonusGold            int          `json:"bonusGold"`
+	ReachedAt            *string      `json:"reachedAt,omitempty"`
+	AllowEarlyWithdrawal bool         `json:"allowEarlyWithdrawal"`
+	Rule                 *SavingsRule `json:"rule,omitempty"`
+	CreatedAt            string       `json:"createdAt"`
+}
+
+type SavingsGoalSettings struct {
+	AllowEarlyWithdrawal bool              `json:"allowEarlyWithdrawal"`
+	Rule                 *SavingsRuleInput `json:"rule,omitempty"`
+}
+
+type SavingsRule struct {
+	Kind           SavingsRuleKind `json:"kind"`
+	Percent        int             `json:"percent"`
+	MaxGoldPerWeek *int            `json:"maxGoldPerWeek,omitempty"`
+}
+
+type SavingsRuleInput struct {
+	Kind           SavingsRuleKind `json:"kind"`
+	Percent        int             `json:"percent"`
+	MaxGoldPerWeek *int            `json:"maxGoldPerWeek,omitempty"`
+}
+
+type SpendingLimits struct {
+	ChildID    string `json:"childId"`
+	DailyGold  *int   `json:"dailyGold,omitempty"`
+	WeeklyGold *int   `json:"weeklyGold,omitempty"`
+}
+
+type SpendingLimitsInput struct {
+	DailyGold  *int `json:"dailyGold,omitempty"`
+	WeeklyGold *int `json:"weeklyGold,omitempty"`
+}
+
+type Subscription struct {
+}
+
 type SubscriptionStatus struct {
-	Active           bool    `json:"active"`
-	CurrentPeriodEnd *string `json:"currentPeriodEnd,omitempty"`
+	Active            bool    `json:"active"`
+	CurrentPeriodEnd  *string `json:"currentPeriodEnd,omitempty"`
+	Status            *string `json:"status,omitempty"`
+	CancelAtPeriodEnd bool    `json:"cancelAtPeriodEnd"`
+	PaymentFailedAt   *string `json:"paymentFailedAt,omitempty"`
+}
+
+type TotpEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
 }
 
 type User struct {
@@ -84,48 +376,84 @@ type User struct {
 	Name string `json:"name"`
 }
 
-type Role string
+type Webhook struct {
+	ID        string         `json:"id"`
+	ParentID  string         `json:"parentId"`
+	URL       string         `json:"url"`
+	Events    []WebhookEvent `json:"events"`
+	CreatedAt string         `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	WebhookID      string                `json:"webhookId"`
+	EventID        string                `json:"eventId"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus *int                  `json:"responseStatus,omitempty"`
+	LastError      *string               `json:"lastError,omitempty"`
+	CreatedAt      string                `json:"createdAt"`
+	NextAttemptAt  *string               `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *string               `json:"deliveredAt,omitempty"`
+}
+
+type WeeklyReport struct {
+	ParentID            string               `json:"parentId"`
+	WeekStart           string               `json:"weekStart"`
+	WeekEnd             string               `json:"weekEnd"`
+	Children            []*ChildWeeklyReport `json:"children"`
+	QuestsCompleted     int                  `json:"questsCompleted"`
+	XpEarned            int                  `json:"xpEarned"`
+	GoldEarned          int                  `json:"goldEarned"`
+	GoldSpent           int                  `json:"goldSpent"`
+	CompletedByCategory []*CategoryCount     `json:"completedByCategory"`
+}
+
+type APIKeyScope string
 
 const (
-	RoleParent Role = "PARENT"
-	RoleChild  Role = "CHILD"
+	APIKeyScopeReadOnly            APIKeyScope = "READ_ONLY"
+	APIKeyScopeCompleteAssignments APIKeyScope = "COMPLETE_ASSIGNMENTS"
+	APIKeyScopeManageQuests        APIKeyScope = "MANAGE_QUESTS"
 )
 
-var AllRole = []Role{
-	RoleParent,
-	RoleChild,
+var AllAPIKeyScope = []APIKeyScope{
+	APIKeyScopeReadOnly,
+	APIKeyScopeCompleteAssignments,
+	APIKeyScopeManageQuests,
 }
 
-func (e Role) IsValid() bool {
+func (e APIKeyScope) IsValid() bool {
 	switch e {
-	case RoleParent, RoleChild:
+	case APIKeyScopeReadOnly, APIKeyScopeCompleteAssignments, APIKeyScopeManageQuests:
 		return true
 	}
 	return false
 }
 
-func (e Role) String() string {
+func (e APIKeyScope) String() string {
 	return string(e)
 }
 
-func (e *Role) UnmarshalGQL(v any) error {
+func (e *APIKeyScope) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = Role(str)
+	*e = APIKeyScope(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid Role", str)
+		return fmt.Errorf("%s is not a valid ApiKeyScope", str)
 	}
 	return nil
 }
 
-func (e Role) MarshalGQL(w io.Writer) {
+func (e APIKeyScope) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *Role) UnmarshalJSON(b []byte) error {
+func (e *APIKeyScope) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -133,7 +461,691 @@ func (e *Role) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e Role) MarshalJSON() ([]byte, error) {
+func (e APIKeyScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "EASY"
+	DifficultyMedium Difficulty = "MEDIUM"
+	DifficultyHard   Difficulty = "HARD"
+)
+
+var AllDifficulty = []Difficulty{
+	DifficultyEasy,
+	DifficultyMedium,
+	DifficultyHard,
+}
+
+func (e Difficulty) IsValid() bool {
+	switch e {
+	case DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return true
+	}
+	return false
+}
+
+func (e Difficulty) String() string {
+	return string(e)
+}
+
+func (e *Difficulty) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Difficulty(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Difficulty", str)
+	}
+	return nil
+}
+
+func (e Difficulty) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Difficulty) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Difficulty) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PayoutStatus string
+
+const (
+	PayoutStatusRequested PayoutStatus = "REQUESTED"
+	PayoutStatusApproved  PayoutStatus = "APPROVED"
+	PayoutStatusPaid      PayoutStatus = "PAID"
+	PayoutStatusDeclined  PayoutStatus = "DECLINED"
+)
+
+var AllPayoutStatus = []PayoutStatus{
+	PayoutStatusRequested,
+	PayoutStatusApproved,
+	PayoutStatusPaid,
+	PayoutStatusDeclined,
+}
+
+func (e PayoutStatus) IsValid() bool {
+	switch e {
+	case PayoutStatusRequested, PayoutStatusApproved, PayoutStatusPaid, PayoutStatusDeclined:
+		return true
+	}
+	return false
+}
+
+func (e PayoutStatus) String() string {
+	return string(e)
+}
+
+func (e *PayoutStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PayoutStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PayoutStatus", str)
+	}
+	return nil
+}
+
+func (e PayoutStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PayoutStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PayoutStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Plan string
+
+const (
+	PlanFree    Plan = "FREE"
+	PlanPremium Plan = "PREMIUM"
+)
+
+var AllPlan = []Plan{
+	PlanFree,
+	PlanPremium,
+}
+
+func (e Plan) IsValid() bool {
+	switch e {
+	case PlanFree, PlanPremium:
+		return true
+	}
+	return false
+}
+
+func (e Plan) String() string {
+	return string(e)
+}
+
+func (e *Plan) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Plan(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Plan", str)
+	}
+	return nil
+}
+
+func (e Plan) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Plan) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Plan) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PurchaseHoldReason string
+
+const (
+	PurchaseHoldReasonRequiresApproval PurchaseHoldReason = "REQUIRES_APPROVAL"
+	PurchaseHoldReasonDailyLimit       PurchaseHoldReason = "DAILY_LIMIT"
+	PurchaseHoldReasonWeeklyLimit      PurchaseHoldReason = "WEEKLY_LIMIT"
+)
+
+var AllPurchaseHoldReason = []PurchaseHoldReason{
+	PurchaseHoldReasonRequiresApproval,
+	PurchaseHoldReasonDailyLimit,
+	PurchaseHoldReasonWeeklyLimit,
+}
+
+func (e PurchaseHoldReason) IsValid() bool {
+	switch e {
+	case PurchaseHoldReasonRequiresApproval, PurchaseHoldReasonDailyLimit, PurchaseHoldReasonWeeklyLimit:
+		return true
+	}
+	return false
+}
+
+func (e PurchaseHoldReason) String() string {
+	return string(e)
+}
+
+func (e *PurchaseHoldReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PurchaseHoldReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PurchaseHoldReason", str)
+	}
+	return nil
+}
+
+func (e PurchaseHoldReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PurchaseHoldReason) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PurchaseHoldReason) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PurchaseRequestStatus string
+
+const (
+	PurchaseRequestStatusPending  PurchaseRequestStatus = "PENDING"
+	PurchaseRequestStatusApproved PurchaseRequestStatus = "APPROVED"
+	PurchaseRequestStatusDeclined PurchaseRequestStatus = "DECLINED"
+)
+
+var AllPurchaseRequestStatus = []PurchaseRequestStatus{
+	PurchaseRequestStatusPending,
+	PurchaseRequestStatusApproved,
+	PurchaseRequestStatusDeclined,
+}
+
+func (e PurchaseRequestStatus) IsValid() bool {
+	switch e {
+	case PurchaseRequestStatusPending, PurchaseRequestStatusApproved, PurchaseRequestStatusDeclined:
+		return true
+	}
+	return false
+}
+
+func (e PurchaseRequestStatus) String() string {
+	return string(e)
+}
+
+func (e *PurchaseRequestStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PurchaseRequestStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PurchaseRequestStatus", str)
+	}
+	return nil
+}
+
+func (e PurchaseRequestStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PurchaseRequestStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PurchaseRequestStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type QuestCategory string
+
+const (
+	QuestCategoryHousehold QuestCategory = "HOUSEHOLD"
+	QuestCategorySchool    QuestCategory = "SCHOOL"
+	QuestCategorySelfCare  QuestCategory = "SELF_CARE"
+	QuestCategoryPets      QuestCategory = "PETS"
+)
+
+var AllQuestCategory = []QuestCategory{
+	QuestCategoryHousehold,
+	QuestCategorySchool,
+	QuestCategorySelfCare,
+	QuestCategoryPets,
+}
+
+func (e QuestCategory) IsValid() bool {
+	switch e {
+	case QuestCategoryHousehold, QuestCategorySchool, QuestCategorySelfCare, QuestCategoryPets:
+		return true
+	}
+	return false
+}
+
+func (e QuestCategory) String() string {
+	return string(e)
+}
+
+func (e *QuestCategory) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = QuestCategory(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid QuestCategory", str)
+	}
+	return nil
+}
+
+func (e QuestCategory) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *QuestCategory) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e QuestCategory) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Recurrence string
+
+const (
+	RecurrenceDaily  Recurrence = "DAILY"
+	RecurrenceWeekly Recurrence = "WEEKLY"
+)
+
+var AllRecurrence = []Recurrence{
+	RecurrenceDaily,
+	RecurrenceWeekly,
+}
+
+func (e Recurrence) IsValid() bool {
+	switch e {
+	case RecurrenceDaily, RecurrenceWeekly:
+		return true
+	}
+	return false
+}
+
+func (e Recurrence) String() string {
+	return string(e)
+}
+
+func (e *Recurrence) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Recurrence(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Recurrence", str)
+	}
+	return nil
+}
+
+func (e Recurrence) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Recurrence) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Recurrence) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleParent Role = "PARENT"
+	RoleChild  Role = "CHILD"
+)
+
+var AllRole = []Role{
+	RoleParent,
+	RoleChild,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleParent, RoleChild:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SavingsRuleKind string
+
+const (
+	SavingsRuleKindInterest SavingsRuleKind = "INTEREST"
+	SavingsRuleKindMatch    SavingsRuleKind = "MATCH"
+)
+
+var AllSavingsRuleKind = []SavingsRuleKind{
+	SavingsRuleKindInterest,
+	SavingsRuleKindMatch,
+}
+
+func (e SavingsRuleKind) IsValid() bool {
+	switch e {
+	case SavingsRuleKindInterest, SavingsRuleKindMatch:
+		return true
+	}
+	return false
+}
+
+func (e SavingsRuleKind) String() string {
+	return string(e)
+}
+
+func (e *SavingsRuleKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SavingsRuleKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SavingsRuleKind", str)
+	}
+	return nil
+}
+
+func (e SavingsRuleKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SavingsRuleKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SavingsRuleKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "PENDING"
+	TransferStatusCompleted TransferStatus = "COMPLETED"
+	TransferStatusDeclined  TransferStatus = "DECLINED"
+)
+
+var AllTransferStatus = []TransferStatus{
+	TransferStatusPending,
+	TransferStatusCompleted,
+	TransferStatusDeclined,
+}
+
+func (e TransferStatus) IsValid() bool {
+	switch e {
+	case TransferStatusPending, TransferStatusCompleted, TransferStatusDeclined:
+		return true
+	}
+	return false
+}
+
+func (e TransferStatus) String() string {
+	return string(e)
+}
+
+func (e *TransferStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TransferStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TransferStatus", str)
+	}
+	return nil
+}
+
+func (e TransferStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TransferStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TransferStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "DEAD"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusSucceeded,
+	WebhookDeliveryStatusDead,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded, WebhookDeliveryStatusDead:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookDeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventAssignmentAssigned  WebhookEvent = "ASSIGNMENT_ASSIGNED"
+	WebhookEventAssignmentCompleted WebhookEvent = "ASSIGNMENT_COMPLETED"
+	WebhookEventItemPurchased       WebhookEvent = "ITEM_PURCHASED"
+	WebhookEventRewardRedeemed      WebhookEvent = "REWARD_REDEEMED"
+	WebhookEventLevelUp             WebhookEvent = "LEVEL_UP"
+)
+
+var AllWebhookEvent = []WebhookEvent{
+	WebhookEventAssignmentAssigned,
+	WebhookEventAssignmentCompleted,
+	WebhookEventItemPurchased,
+	WebhookEventRewardRedeemed,
+	WebhookEventLevelUp,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventAssignmentAssigned, WebhookEventAssignmentCompleted, WebhookEventItemPurchased, WebhookEventRewardRedeemed, WebhookEventLevelUp:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (e *WebhookEvent) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEvent", str)
+	}
+	return nil
+}
+
+func (e WebhookEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookEvent) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
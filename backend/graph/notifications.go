@@ -0,0 +1,19 @@
+package graph
+
+import (
+    "net/mail"
+    "strings"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// normalizeEmail trims an optional address and checks that it is a bare
+// address rather than a display-name form; empty means none.
+func normalizeEmail(email *string) (*string, error) {
+    if email == nil { return nil, nil }
+    v := strings.TrimSpace(*email)
+    if v == "" { return nil, nil }
+    a, err := mail.ParseAddress(v)
+    if err != nil || a.Address != v { return nil, apperr.Invalid("input.email", "must be an email address") }
+    return &v, nil
+}
@@ -0,0 +1,29 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "context"
+)
+
+// UpdateNotificationPreferences is the resolver for the updateNotificationPreferences field.
+func (r *mutationResolver) UpdateNotificationPreferences(ctx context.Context, input model.NotificationPreferencesInput) (*model.NotificationPreferences, error) {
+    if err := r.authorizeParent(ctx, input.ParentID); err != nil { return nil, err }
+    email, err := normalizeEmail(input.Email)
+    if err != nil { return nil, err }
+    p := &model.NotificationPreferences{
+        ParentID: input.ParentID, Email: email,
+        ChoreSubmitted: input.ChoreSubmitted, ItemPurchased: input.ItemPurchased, WeeklyDigest: input.WeeklyDigest,
+    }
+    if err := r.Repo.SaveNotificationPreferences(ctx, p); err != nil { return nil, err }
+    return p, nil
+}
+
+// NotificationPreferences is the resolver for the notificationPreferences field.
+func (r *queryResolver) NotificationPreferences(ctx context.Context, parentID string) (*model.NotificationPreferences, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.GetNotificationPreferences(ctx, parentID)
+}
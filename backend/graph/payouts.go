@@ -0,0 +1,31 @@
+package graph
+
+import (
+    "math"
+    "strings"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// exchangeRateFrom validates input; nil input disables payouts.
+func exchangeRateFrom(in *model.ExchangeRateInput) (*model.ExchangeRate, error) {
+    if in == nil { return nil, nil }
+    cur := strings.ToUpper(in.Currency)
+    for _, c := range cur {
+        if c < 'A' || c > 'Z' { return nil, apperr.Invalid("input.currency", "must be an ISO 4217 code such as USD") }
+    }
+    return &model.ExchangeRate{Currency: cur, Gold: in.Gold, MinorUnits: in.MinorUnits}, nil
+}
+
+// payoutAmount converts gold at rate, rounding down to whole minor units.
+func payoutAmount(rate *model.ExchangeRate, gold int) (int, error) {
+    amount := int64(gold) * int64(rate.MinorUnits) / int64(rate.Gold)
+    if amount < 1 { return 0, apperr.Invalid("gold", "worth less than one minor unit of %s; at least %d gold is needed", rate.Currency, minGold(rate)) }
+    if amount > math.MaxInt32 { return 0, apperr.Invalid("gold", "amount too large") }
+    return int(amount), nil
+}
+
+func minGold(rate *model.ExchangeRate) int {
+    return (rate.Gold + rate.MinorUnits - 1) / rate.MinorUnits
+}
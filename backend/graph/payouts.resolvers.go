@@ -0,0 +1,78 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "context"
+)
+
+// SetExchangeRate is the resolver for the setExchangeRate field.
+func (r *mutationResolver) SetExchangeRate(ctx context.Context, parentID string, input *model.ExchangeRateInput) (*model.ExchangeRate, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    rate, err := exchangeRateFrom(input)
+    if err != nil { return nil, err }
+    if err := r.Repo.SetExchangeRate(ctx, parentID, rate); err != nil { return nil, err }
+    return rate, nil
+}
+
+// RequestPayout is the resolver for the requestPayout field.
+func (r *mutationResolver) RequestPayout(ctx context.Context, childID string, gold int) (*model.Payout, error) {
+    if err := r.authorizeChild(ctx, childID); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    if err := r.writeChild(ctx, childID); err != nil { return nil, err }
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    rate, err := r.Repo.GetExchangeRate(ctx, ch.ParentID)
+    if err != nil { return nil, err }
+    if rate == nil { return nil, apperr.New(apperr.Validation, "payouts are not enabled for this household") }
+    amount, err := payoutAmount(rate, gold)
+    if err != nil { return nil, err }
+    return r.Repo.RequestPayout(ctx, childID, gold, amount, rate.Currency)
+}
+
+// ApprovePayout is the resolver for the approvePayout field.
+func (r *mutationResolver) ApprovePayout(ctx context.Context, parentID string, id string) (*model.Payout, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.UpdatePayout(ctx, parentID, id, model.PayoutStatusApproved)
+}
+
+// MarkPayoutPaid is the resolver for the markPayoutPaid field.
+func (r *mutationResolver) MarkPayoutPaid(ctx context.Context, parentID string, id string) (*model.Payout, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.UpdatePayout(ctx, parentID, id, model.PayoutStatusPaid)
+}
+
+// DeclinePayout is the resolver for the declinePayout field.
+func (r *mutationResolver) DeclinePayout(ctx context.Context, parentID string, id string) (*model.Payout, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.UpdatePayout(ctx, parentID, id, model.PayoutStatusDeclined)
+}
+
+// ExchangeRate is the resolver for the exchangeRate field.
+func (r *queryResolver) ExchangeRate(ctx context.Context, parentID string) (*model.ExchangeRate, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    return r.Repo.GetExchangeRate(ctx, parentID)
+}
+
+// Payouts is the resolver for the payouts field.
+func (r *queryResolver) Payouts(ctx context.Context, parentID string, status *model.PayoutStatus) ([]*model.Payout, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    list, err := r.Repo.ListPayouts(ctx, parentID)
+    if err != nil || status == nil { return list, err }
+    out := list[:0]
+    for _, p := range list {
+        if p.Status == *status { out = append(out, p) }
+    }
+    return out, nil
+}
+
+// MyPayouts is the resolver for the myPayouts field.
+func (r *queryResolver) MyPayouts(ctx context.Context, childID string) ([]*model.Payout, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return r.Repo.ListChildPayouts(ctx, childID)
+}
@@ -0,0 +1,34 @@
+package graph
+
+import (
+    "strings"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/push"
+)
+
+const maxPushSubscriptionsPerChild = 10
+
+// checkPushKeys validates the subscription keys the browser reported.
+func checkPushKeys(in model.PushSubscriptionInput) error {
+    _, _, err := push.Subscription{Endpoint: in.Endpoint, P256dh: in.P256dh, Auth: in.Auth}.Keys()
+    if err == nil { return nil }
+    field, msg, _ := strings.Cut(err.Error(), ": ")
+    return apperr.Invalid("input."+field, "%s", msg)
+}
+
+// quietHoursFrom validates input; nil input means no quiet hours.
+func quietHoursFrom(in *model.QuietHoursInput) (*model.QuietHours, error) {
+    if in == nil { return nil, nil }
+    start, err := push.ParseClock(in.Start)
+    if err != nil { return nil, apperr.Invalid("input.start", "must be HH:MM") }
+    end, err := push.ParseClock(in.End)
+    if err != nil { return nil, apperr.Invalid("input.end", "must be HH:MM") }
+    if start == end { return nil, apperr.Invalid("input.end", "must differ from start") }
+    if _, err := time.LoadLocation(in.TimeZone); err != nil || in.TimeZone == "Local" {
+        return nil, apperr.Invalid("input.timeZone", "unknown time zone")
+    }
+    return &model.QuietHours{Start: in.Start, End: in.End, TimeZone: in.TimeZone}, nil
+}
@@ -0,0 +1,76 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "context"
+)
+
+// RegisterPushSubscription is the resolver for the registerPushSubscription field.
+func (r *mutationResolver) RegisterPushSubscription(ctx context.Context, input model.PushSubscriptionInput) (*model.PushSubscription, error) {
+    if err := r.authorizeSignedIn(ctx); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, input.ChildID); err != nil { return nil, err }
+    if err := r.validateOutboundURL("input.endpoint", input.Endpoint); err != nil { return nil, err }
+    if err := checkPushKeys(input); err != nil { return nil, err }
+    ch, err := r.Repo.GetChild(ctx, input.ChildID)
+    if err != nil { return nil, err }
+    existing, err := r.Repo.ListPushSubscriptions(ctx, input.ChildID)
+    if err != nil { return nil, err }
+    if len(existing) >= maxPushSubscriptionsPerChild {
+        known := false
+        for _, t := range existing {
+            known = known || t.Subscription.Endpoint == input.Endpoint
+        }
+        if !known { return nil, apperr.New(apperr.Validation, "at most %d devices per child", maxPushSubscriptionsPerChild) }
+    }
+    return r.Repo.SavePushSubscription(ctx, ch.ParentID, input.ChildID, input.Endpoint, input.P256dh, input.Auth)
+}
+
+// DeletePushSubscription is the resolver for the deletePushSubscription field.
+func (r *mutationResolver) DeletePushSubscription(ctx context.Context, childID string, id string) (*model.PushSubscription, error) {
+    if err := r.authorizeChild(ctx, childID); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return r.Repo.DeletePushSubscription(ctx, childID, id)
+}
+
+// SetQuietHours is the resolver for the setQuietHours field.
+func (r *mutationResolver) SetQuietHours(ctx context.Context, childID string, input *model.QuietHoursInput) (*model.QuietHours, error) {
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    if err := r.authorizeParent(ctx, ch.ParentID); err != nil { return nil, err }
+    q, err := quietHoursFrom(input)
+    if err != nil { return nil, err }
+    if err := r.Repo.SetQuietHours(ctx, childID, q); err != nil { return nil, err }
+    return q, nil
+}
+
+// VapidPublicKey is the resolver for the vapidPublicKey field.
+func (r *queryResolver) VapidPublicKey(ctx context.Context) (*string, error) {
+    if r.Push == nil { return nil, nil }
+    k := r.Push.VAPID.PublicKey()
+    return &k, nil
+}
+
+// PushSubscriptions is the resolver for the pushSubscriptions field.
+func (r *queryResolver) PushSubscriptions(ctx context.Context, childID string) ([]*model.PushSubscription, error) {
+    if err := r.authorizeChild(ctx, childID); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    targets, err := r.Repo.ListPushSubscriptions(ctx, childID)
+    if err != nil { return nil, err }
+    out := make([]*model.PushSubscription, 0, len(targets))
+    for _, t := range targets {
+        out = append(out, t.Subscription)
+    }
+    return out, nil
+}
+
+// QuietHours is the resolver for the quietHours field.
+func (r *queryResolver) QuietHours(ctx context.Context, childID string) (*model.QuietHours, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return r.Repo.GetQuietHours(ctx, childID)
+}
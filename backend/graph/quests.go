@@ -0,0 +1,33 @@
+package graph
+
+import (
+    "strings"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+const (
+    maxQuestTags      = 10
+    maxQuestTagLength = 30
+)
+
+// questTags trims and lower-cases tags and drops blanks and duplicates.
+func questTags(field string, tags []string) ([]string, error) {
+    out := []string{}
+    for _, t := range tags {
+        t = strings.ToLower(strings.TrimSpace(t))
+        if t == "" || containsFold(out, t) { continue }
+        if len(t) > maxQuestTagLength { return nil, apperr.Invalid(field, "tags are at most %d characters", maxQuestTagLength) }
+        out = append(out, t)
+    }
+    if len(out) > maxQuestTags { return nil, apperr.Invalid(field, "at most %d tags", maxQuestTags) }
+    return out, nil
+}
+
+// questMatches reports whether q passes the quests(parentId) filters.
+func questMatches(q *model.Quest, category *model.QuestCategory, tag *string, difficulty *model.Difficulty) bool {
+    if category != nil && (q.Category == nil || *q.Category != *category) { return false }
+    if difficulty != nil && (q.Difficulty == nil || *q.Difficulty != *difficulty) { return false }
+    return tag == nil || containsFold(q.Tags, strings.TrimSpace(*tag))
+}
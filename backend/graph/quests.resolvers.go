@@ -0,0 +1,34 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "context"
+)
+
+// SetDifficultyMultipliers is the resolver for the setDifficultyMultipliers field.
+func (r *mutationResolver) SetDifficultyMultipliers(ctx context.Context, parentID string, input *model.DifficultyMultipliersInput) (*model.DifficultyMultipliers, error) {
+    if err := r.authorize(ctx, parentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    var m *model.DifficultyMultipliers
+    if input != nil { m = &model.DifficultyMultipliers{Easy: input.Easy, Medium: input.Medium, Hard: input.Hard} }
+    if err := r.Repo.SetDifficultyMultipliers(ctx, parentID, m); err != nil { return nil, err }
+    return m, nil
+}
+
+// UpdateQuestDetails is the resolver for the updateQuestDetails field.
+func (r *mutationResolver) UpdateQuestDetails(ctx context.Context, questID string, input model.QuestDetailsInput) (*model.Quest, error) {
+    q, err := r.Repo.GetQuestByID(ctx, questID)
+    if err != nil { return nil, err }
+    if err := r.authorize(ctx, q.ParentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    if input.Tags, err = questTags("input.tags", input.Tags); err != nil { return nil, err }
+    return r.Repo.UpdateQuestDetails(ctx, questID, input)
+}
+
+// DifficultyMultipliers is the resolver for the difficultyMultipliers field.
+func (r *queryResolver) DifficultyMultipliers(ctx context.Context, parentID string) (*model.DifficultyMultipliers, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    return r.Repo.GetDifficultyMultipliers(ctx, parentID)
+}
@@ -0,0 +1,26 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/report"
+    "context"
+    "time"
+)
+
+// WeeklyReport is the resolver for the weeklyReport field.
+func (r *queryResolver) WeeklyReport(ctx context.Context, parentID string, weekStart *string) (*model.WeeklyReport, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    now := time.Now()
+    week := report.WeekStart(now).AddDate(0, 0, -7)
+    if weekStart != nil {
+        d, err := time.Parse(time.DateOnly, *weekStart)
+        if err != nil { return nil, apperr.Invalid("weekStart", "must be a date (YYYY-MM-DD)") }
+        week = report.WeekStart(d)
+    }
+    return report.Weekly(ctx, r.Repo, parentID, week, now)
+}
@@ -5,10 +5,22 @@ package graph
 // It serves as dependency injection for your app, add any dependencies you require here.
 import (
     "sync"
+    "chorequest/backend/internal/config"
+    "chorequest/backend/internal/events"
+    "chorequest/backend/internal/push"
     repopkg "chorequest/backend/internal/repo"
+    "github.com/stripe/stripe-go/v76/client"
 )
 
 type Resolver struct{
     mu sync.Mutex
     Repo repopkg.Repo
+    // Events carries domain changes, published by the outbox dispatcher, to subscriptions.
+    Events *events.Bus
+    // Config holds server settings; nil applies dev defaults.
+    Config *config.Config
+    // Push sends Web Push notifications; nil disables push.
+    Push *push.Client
+    // Stripe calls the Stripe API with the server's key; nil returns placeholder billing URLs.
+    Stripe *client.API
 }
@@ -0,0 +1,24 @@
+package graph
+
+import (
+    "context"
+
+    "chorequest/backend/graph/model"
+)
+
+const maxSavingsGoalsPerChild = 20
+
+// savingsRuleFrom converts input; nil input means no rule.
+func savingsRuleFrom(in *model.SavingsRuleInput) *model.SavingsRule {
+    if in == nil { return nil }
+    return &model.SavingsRule{Kind: in.Kind, Percent: in.Percent, MaxGoldPerWeek: in.MaxGoldPerWeek}
+}
+
+// authorizeSaver lets the child or their parent move gold of a goal.
+func (r *Resolver) authorizeSaver(ctx context.Context, goalID string) error {
+    g, err := r.Repo.GetSavingsGoal(ctx, goalID)
+    if err != nil { return err }
+    if err := r.authorize(ctx, g.ParentID); err != nil { return err }
+    if err := r.authorizeChildViewer(ctx, g.ChildID); err != nil { return err }
+    return r.plans().WriteChild(ctx, g.ParentID, g.ChildID)
+}
@@ -0,0 +1,54 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/report"
+    "context"
+    "time"
+)
+
+// CreateSavingsGoal is the resolver for the createSavingsGoal field.
+func (r *mutationResolver) CreateSavingsGoal(ctx context.Context, input model.NewSavingsGoal) (*model.SavingsGoal, error) {
+    if err := r.authorizeChild(ctx, input.ChildID); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, input.ChildID); err != nil { return nil, err }
+    if err := r.writeChild(ctx, input.ChildID); err != nil { return nil, err }
+    ch, err := r.Repo.GetChild(ctx, input.ChildID)
+    if err != nil { return nil, err }
+    existing, err := r.Repo.ListSavingsGoals(ctx, input.ChildID)
+    if err != nil { return nil, err }
+    if len(existing) >= maxSavingsGoalsPerChild { return nil, apperr.New(apperr.Validation, "at most %d savings goals per child", maxSavingsGoalsPerChild) }
+    return r.Repo.CreateSavingsGoal(ctx, ch.ParentID, input.ChildID, input.Name, input.TargetGold)
+}
+
+// DepositToGoal is the resolver for the depositToGoal field.
+func (r *mutationResolver) DepositToGoal(ctx context.Context, goalID string, gold int) (*model.SavingsGoal, error) {
+    if err := r.authorizeSaver(ctx, goalID); err != nil { return nil, err }
+    return r.Repo.MoveSavings(ctx, goalID, gold)
+}
+
+// WithdrawFromGoal is the resolver for the withdrawFromGoal field.
+func (r *mutationResolver) WithdrawFromGoal(ctx context.Context, goalID string, gold int) (*model.SavingsGoal, error) {
+    if err := r.authorizeSaver(ctx, goalID); err != nil { return nil, err }
+    return r.Repo.MoveSavings(ctx, goalID, -gold)
+}
+
+// UpdateSavingsGoal is the resolver for the updateSavingsGoal field.
+func (r *mutationResolver) UpdateSavingsGoal(ctx context.Context, goalID string, input model.SavingsGoalSettings) (*model.SavingsGoal, error) {
+    g, err := r.Repo.GetSavingsGoal(ctx, goalID)
+    if err != nil { return nil, err }
+    if err := r.authorizeParent(ctx, g.ParentID); err != nil { return nil, err }
+    week := report.WeekStart(time.Now()).Format(time.DateOnly)
+    return r.Repo.UpdateSavingsGoal(ctx, goalID, input.AllowEarlyWithdrawal, savingsRuleFrom(input.Rule), week)
+}
+
+// SavingsGoals is the resolver for the savingsGoals field.
+func (r *queryResolver) SavingsGoals(ctx context.Context, childID string) ([]*model.SavingsGoal, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return r.Repo.ListSavingsGoals(ctx, childID)
+}
@@ -0,0 +1,3 @@
+package graph
+
+const maxQuestSchedulesPerParent = 50
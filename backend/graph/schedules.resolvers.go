@@ -0,0 +1,46 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "context"
+    "time"
+)
+
+// ScheduleQuest is the resolver for the scheduleQuest field.
+func (r *mutationResolver) ScheduleQuest(ctx context.Context, questID string, childID string, recurrence model.Recurrence) (*model.QuestSchedule, error) {
+    if err := r.authorizeChild(ctx, childID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    q, err := r.Repo.GetQuestByID(ctx, questID)
+    if err != nil { return nil, err }
+    if q.ParentID != ch.ParentID { return nil, apperr.New(apperr.NotFound, "quest not found") }
+    if err := r.plans().Schedule(ctx, ch.ParentID); err != nil { return nil, err }
+    existing, err := r.Repo.ListQuestSchedules(ctx, ch.ParentID)
+    if err != nil { return nil, err }
+    if len(existing) >= maxQuestSchedulesPerParent { return nil, apperr.New(apperr.Validation, "at most %d schedules per household", maxQuestSchedulesPerParent) }
+    return r.Repo.CreateQuestSchedule(ctx, ch.ParentID, questID, childID, recurrence, time.Now())
+}
+
+// DeleteQuestSchedule is the resolver for the deleteQuestSchedule field.
+func (r *mutationResolver) DeleteQuestSchedule(ctx context.Context, parentID string, id string) (*model.QuestSchedule, error) {
+    if err := r.authorize(ctx, parentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    return r.Repo.DeleteQuestSchedule(ctx, parentID, id)
+}
+
+// QuestSchedules is the resolver for the questSchedules field.
+func (r *queryResolver) QuestSchedules(ctx context.Context, parentID string) ([]*model.QuestSchedule, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    h, err := r.plans().For(ctx, parentID)
+    if err != nil { return nil, err }
+    list, err := r.Repo.ListQuestSchedules(ctx, parentID)
+    if err != nil { return nil, err }
+    for _, s := range list {
+        s.Paused = !h.Limits.RecurringSchedules
+    }
+    return list, nil
+}
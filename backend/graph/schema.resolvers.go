@@ -6,62 +6,135 @@ package graph
 
 import (
     "chorequest/backend/graph/model"
+    "chorequest/backend/internal/billing"
+    "chorequest/backend/internal/apperr"
     "context"
     "fmt"
-    "os"
+    "time"
     stripe "github.com/stripe/stripe-go/v76"
-    "github.com/stripe/stripe-go/v76/checkout/session"
 )
 
+// Level is the resolver for the level field.
+func (r *childResolver) Level(ctx context.Context, obj *model.Child) (int, error) {
+    return model.LevelForXP(obj.Xp), nil
+}
+
+// Age is the resolver for the age field.
+func (r *childResolver) Age(ctx context.Context, obj *model.Child) (*int, error) {
+    age, ok := model.AgeOn(obj.Birthdate, time.Now())
+    if !ok { return nil, nil }
+    return &age, nil
+}
+
 // CreateChild is the resolver for the createChild field.
 func (r *mutationResolver) CreateChild(ctx context.Context, input model.NewChild) (*model.Child, error) {
+    if err := r.authorize(ctx, input.ParentID); err != nil { return nil, err }
+    if err := r.plans().AddChild(ctx, input.ParentID); err != nil { return nil, err }
+    birthdate, err := birthdateFrom("input.birthdate", input.Birthdate, time.Now().UTC())
+    if err != nil { return nil, err }
+    input.Birthdate = birthdate
 	return r.Repo.CreateChild(ctx, input)
 }
 
 // CreateQuest is the resolver for the createQuest field.
 func (r *mutationResolver) CreateQuest(ctx context.Context, input model.NewQuest) (*model.Quest, error) {
+    if err := r.authorize(ctx, input.ParentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    if err := r.plans().AddQuest(ctx, input.ParentID); err != nil { return nil, err }
+    tags, err := questTags("input.tags", input.Tags)
+    if err != nil { return nil, err }
+    input.Tags = tags
 	return r.Repo.CreateQuest(ctx, input)
 }
 
 // AssignQuest is the resolver for the assignQuest field.
 func (r *mutationResolver) AssignQuest(ctx context.Context, questID string, childID string) (*model.Assignment, error) {
-	return r.Repo.AssignQuest(ctx, questID, childID)
+    if err := r.authorizeChild(ctx, childID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    q, err := r.Repo.GetQuestByID(ctx, questID)
+    if err != nil { return nil, err }
+    if err := r.plans().Assign(ctx, q.ParentID, questID, childID); err != nil { return nil, err }
+    return r.Repo.AssignQuest(ctx, questID, childID)
 }
 
 // CreateReward is the resolver for the createReward field.
 func (r *mutationResolver) CreateReward(ctx context.Context, input model.NewReward) (*model.Reward, error) {
+    if err := r.authorize(ctx, input.ParentID); err != nil { return nil, err }
 	return r.Repo.CreateReward(ctx, input)
 }
 
 // CompleteAssignment is the resolver for the completeAssignment field.
 func (r *mutationResolver) CompleteAssignment(ctx context.Context, assignmentID string) (*model.Assignment, error) {
-	return r.Repo.CompleteAssignment(ctx, assignmentID)
+    a, err := r.Repo.GetAssignment(ctx, assignmentID)
+    if err != nil { return nil, err }
+    if err := r.authorize(ctx, a.Quest.ParentID, model.APIKeyScopeCompleteAssignments); err != nil { return nil, err }
+    if err := r.plans().WriteChild(ctx, a.Quest.ParentID, a.ChildID); err != nil { return nil, err }
+    return r.Repo.CompleteAssignment(ctx, assignmentID)
+}
+
+// RedeemReward is the resolver for the redeemReward field.
+func (r *mutationResolver) RedeemReward(ctx context.Context, childID string, rewardID string) (*model.Reward, error) {
+    if err := r.authorizeChild(ctx, childID); err != nil { return nil, err }
+    if err := r.writeChild(ctx, childID); err != nil { return nil, err }
+    return r.Repo.RedeemReward(ctx, childID, rewardID)
 }
 
 // PurchaseItem is the resolver for the purchaseItem field.
 func (r *mutationResolver) PurchaseItem(ctx context.Context, childID string, itemName string, priceGold int) (*model.Child, error) {
-	return r.Repo.PurchaseItem(ctx, childID, itemName, priceGold)
+    if err := r.authorizeChild(ctx, childID); err != nil { return nil, err }
+    if err := r.writeChild(ctx, childID); err != nil { return nil, err }
+    return r.purchase(ctx, childID, itemName, priceGold)
 }
 
 // CreateCheckoutSession is the resolver for the createCheckoutSession field.
 func (r *mutationResolver) CreateCheckoutSession(ctx context.Context, parentID string, successURL string, cancelURL string) (string, error) {
-    secret := os.Getenv("STRIPE_SECRET")
-    price := os.Getenv("STRIPE_PRICE_ID")
-    if secret == "" || price == "" {
+    if err := requireSelf(ctx, parentID); err != nil { return "", err }
+    if err := validateURL("successUrl", successURL); err != nil { return "", err }
+    if err := validateURL("cancelUrl", cancelURL); err != nil { return "", err }
+    b, err := r.Repo.GetBilling(ctx, parentID)
+    if err != nil { return "", err }
+    if b.Active() { return "", apperr.New(apperr.Conflict, "already subscribed; manage the plan in the billing portal") }
+    if r.Stripe == nil {
         return "https://example.com/checkout", nil
     }
-    stripe.Key = secret
+    customer, err := r.stripeCustomer(ctx, parentID, b)
+    if err != nil { return "", err }
     params := &stripe.CheckoutSessionParams{
         Mode:       stripe.String(string(stripe.CheckoutSessionModeSubscription)),
+        Customer:   stripe.String(customer),
         SuccessURL: stripe.String(successURL),
         CancelURL:  stripe.String(cancelURL),
         LineItems: []*stripe.CheckoutSessionLineItemParams{{
-            Price:    stripe.String(price),
+            Price:    stripe.String(r.Config.StripePriceID),
             Quantity: stripe.Int64(1),
         }},
         ClientReferenceID: stripe.String(parentID),
+        SubscriptionData: &stripe.CheckoutSessionSubscriptionDataParams{
+            Metadata: map[string]string{billing.ParentMetadataKey: parentID},
+        },
+    }
+    // Only a household's first subscription gets the trial.
+    if days := r.Config.StripeTrialDays; days > 0 && b.SubscriptionID == "" {
+        params.SubscriptionData.TrialPeriodDays = stripe.Int64(int64(days))
     }
-    sess, err := session.New(params)
+    params.Context = ctx
+    sess, err := r.Stripe.CheckoutSessions.New(params)
+    if err != nil { return "", fmt.Errorf("stripe: %w", err) }
+    return sess.URL, nil
+}
+
+// CreateBillingPortalSession is the resolver for the createBillingPortalSession field.
+func (r *mutationResolver) CreateBillingPortalSession(ctx context.Context, parentID string, returnURL string) (string, error) {
+    if err := requireSelf(ctx, parentID); err != nil { return "", err }
+    if err := validateURL("returnUrl", returnURL); err != nil { return "", err }
+    if r.Stripe == nil {
+        return "https://example.com/billing-portal", nil
+    }
+    b, err := r.Repo.GetBilling(ctx, parentID)
+    if err != nil { return "", err }
+    customer, err := r.stripeCustomer(ctx, parentID, b)
+    if err != nil { return "", err }
+    params := &stripe.BillingPortalSessionParams{Customer: stripe.String(customer), ReturnURL: stripe.String(returnURL)}
+    params.Context = ctx
+    sess, err := r.Stripe.BillingPortalSessions.New(params)
     if err != nil { return "", fmt.Errorf("stripe: %w", err) }
     return sess.URL, nil
 }
@@ -73,35 +146,51 @@ func (r *queryResolver) Health(ctx context.Context) (string, error) {
 
 // Children is the resolver for the children field.
 func (r *queryResolver) Children(ctx context.Context, parentID string) ([]*model.Child, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
 	return r.Repo.ListChildren(ctx, parentID)
 }
 
 // Quests is the resolver for the quests field.
-func (r *queryResolver) Quests(ctx context.Context, parentID string) ([]*model.Quest, error) {
-	return r.Repo.ListQuests(ctx, parentID)
+func (r *queryResolver) Quests(ctx context.Context, parentID string, category *model.QuestCategory, tag *string, difficulty *model.Difficulty) ([]*model.Quest, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    list, err := r.Repo.ListQuests(ctx, parentID)
+    if err != nil { return nil, err }
+    out := list[:0]
+    for _, q := range list {
+        if questMatches(q, category, tag, difficulty) { out = append(out, q) }
+    }
+    return out, nil
 }
 
 // Rewards is the resolver for the rewards field.
 func (r *queryResolver) Rewards(ctx context.Context, parentID string) ([]*model.Reward, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
 	return r.Repo.ListRewards(ctx, parentID)
 }
 
 // MyAssignments is the resolver for the myAssignments field.
 func (r *queryResolver) MyAssignments(ctx context.Context, childID string) ([]*model.Assignment, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
 	return r.Repo.ListAssignmentsForChild(ctx, childID)
 }
 
 // SubscriptionStatus is the resolver for the subscriptionStatus field.
 func (r *queryResolver) SubscriptionStatus(ctx context.Context, parentID string) (*model.SubscriptionStatus, error) {
-    // Placeholder: implement with Stripe customer/subscription lookup later
-    return &model.SubscriptionStatus{Active: false, CurrentPeriodEnd: nil}, nil
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    b, err := r.Repo.GetBilling(ctx, parentID)
+    if err != nil { return nil, err }
+    return subscriptionStatus(b), nil
 }
 
+// Child returns ChildResolver implementation.
+func (r *Resolver) Child() ChildResolver { return &childResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+type childResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
@@ -0,0 +1,76 @@
+package graph
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/report"
+)
+
+const (
+    maxApprovalItems    = 100
+    maxApprovalItemName = 120
+)
+
+// approvalItemsFrom trims names and drops blanks and duplicates (ignoring case).
+func approvalItemsFrom(names []string) ([]string, error) {
+    out := []string{}
+    for _, n := range names {
+        n = strings.TrimSpace(n)
+        if n == "" || containsFold(out, n) { continue }
+        if len(n) > maxApprovalItemName { return nil, apperr.Invalid("names", "names are at most %d characters", maxApprovalItemName) }
+        out = append(out, n)
+    }
+    if len(out) > maxApprovalItems { return nil, apperr.Invalid("names", "at most %d items", maxApprovalItems) }
+    return out, nil
+}
+
+func containsFold(names []string, name string) bool {
+    for _, n := range names {
+        if strings.EqualFold(n, name) { return true }
+    }
+    return false
+}
+
+// purchase buys the item, unless the purchase breaks a spending rule: then
+// the gold is held for the parent to decide. Either way it returns the child's
+// balance afterwards. Only the household's signed-in parent is not limited.
+func (r *Resolver) purchase(ctx context.Context, childID, itemName string, priceGold int) (*model.Child, error) {
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    if isHouseholdParent(ctx, ch.ParentID) { return r.Repo.PurchaseItem(ctx, childID, itemName, priceGold) }
+    reason, err := r.holdReason(ctx, ch, itemName, priceGold, time.Now().UTC())
+    if err != nil { return nil, err }
+    if reason == nil { return r.Repo.PurchaseItem(ctx, childID, itemName, priceGold) }
+    _, after, err := r.Repo.HoldPurchase(ctx, childID, itemName, priceGold, *reason)
+    return after, err
+}
+
+// holdReason reports why the child's purchase must wait for approval, or nil
+// if it may go through. Caps count purchases made so far this UTC day and week;
+// held purchases only count once approved.
+func (r *Resolver) holdReason(ctx context.Context, ch *model.Child, itemName string, priceGold int, now time.Time) (*model.PurchaseHoldReason, error) {
+    hold := func(h model.PurchaseHoldReason) (*model.PurchaseHoldReason, error) { return &h, nil }
+    names, err := r.Repo.GetApprovalItems(ctx, ch.ParentID)
+    if err != nil { return nil, err }
+    if containsFold(names, strings.TrimSpace(itemName)) { return hold(model.PurchaseHoldReasonRequiresApproval) }
+    l, err := r.Repo.GetSpendingLimits(ctx, ch.ID)
+    if err != nil { return nil, err }
+    if l.DailyGold == nil && l.WeeklyGold == nil { return nil, nil }
+
+    week := report.WeekStart(now)
+    day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+    purchases, err := r.Repo.ListPurchases(ctx, ch.ID, week.Format(time.RFC3339), week.AddDate(0, 0, 7).Format(time.RFC3339))
+    if err != nil { return nil, err }
+    spentToday, spentWeek := priceGold, priceGold
+    for _, p := range purchases {
+        spentWeek += p.PriceGold
+        if p.PurchasedAt >= day { spentToday += p.PriceGold }
+    }
+    if l.DailyGold != nil && spentToday > *l.DailyGold { return hold(model.PurchaseHoldReasonDailyLimit) }
+    if l.WeeklyGold != nil && spentWeek > *l.WeeklyGold { return hold(model.PurchaseHoldReasonWeeklyLimit) }
+    return nil, nil
+}
@@ -0,0 +1,73 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "context"
+)
+
+// SetSpendingLimits is the resolver for the setSpendingLimits field.
+func (r *mutationResolver) SetSpendingLimits(ctx context.Context, childID string, input model.SpendingLimitsInput) (*model.SpendingLimits, error) {
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    if err := r.authorizeParent(ctx, ch.ParentID); err != nil { return nil, err }
+    l := &model.SpendingLimits{ChildID: childID, DailyGold: input.DailyGold, WeeklyGold: input.WeeklyGold}
+    if err := r.Repo.SetSpendingLimits(ctx, ch.ParentID, l); err != nil { return nil, err }
+    return l, nil
+}
+
+// SetApprovalItems is the resolver for the setApprovalItems field.
+func (r *mutationResolver) SetApprovalItems(ctx context.Context, parentID string, names []string) ([]string, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    names, err := approvalItemsFrom(names)
+    if err != nil { return nil, err }
+    if err := r.Repo.SetApprovalItems(ctx, parentID, names); err != nil { return nil, err }
+    return names, nil
+}
+
+// ApprovePurchase is the resolver for the approvePurchase field.
+func (r *mutationResolver) ApprovePurchase(ctx context.Context, parentID string, id string) (*model.PurchaseRequest, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.DecidePurchase(ctx, parentID, id, true)
+}
+
+// DeclinePurchase is the resolver for the declinePurchase field.
+func (r *mutationResolver) DeclinePurchase(ctx context.Context, parentID string, id string) (*model.PurchaseRequest, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.DecidePurchase(ctx, parentID, id, false)
+}
+
+// SpendingLimits is the resolver for the spendingLimits field.
+func (r *queryResolver) SpendingLimits(ctx context.Context, childID string) (*model.SpendingLimits, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return r.Repo.GetSpendingLimits(ctx, childID)
+}
+
+// ApprovalItems is the resolver for the approvalItems field.
+func (r *queryResolver) ApprovalItems(ctx context.Context, parentID string) ([]string, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    return r.Repo.GetApprovalItems(ctx, parentID)
+}
+
+// PurchaseRequests is the resolver for the purchaseRequests field.
+func (r *queryResolver) PurchaseRequests(ctx context.Context, parentID string, status *model.PurchaseRequestStatus) ([]*model.PurchaseRequest, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    list, err := r.Repo.ListPurchaseRequests(ctx, parentID)
+    if err != nil || status == nil { return list, err }
+    out := list[:0]
+    for _, p := range list {
+        if p.Status == *status { out = append(out, p) }
+    }
+    return out, nil
+}
+
+// MyPurchaseRequests is the resolver for the myPurchaseRequests field.
+func (r *queryResolver) MyPurchaseRequests(ctx context.Context, childID string) ([]*model.PurchaseRequest, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return r.Repo.ListChildPurchaseRequests(ctx, childID)
+}
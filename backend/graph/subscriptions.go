@@ -0,0 +1,44 @@
+package graph
+
+import (
+    "context"
+
+    "chorequest/backend/internal/events"
+)
+
+// changesBalance reports whether events of type t carry a child whose XP or
+// gold changed; other events may include the child only for context.
+func changesBalance(t events.Type) bool {
+    switch t {
+    case events.AssignmentCompleted, events.ItemPurchased, events.PayoutRequested, events.PayoutDeclined,
+        events.SavingsDeposited, events.SavingsWithdrawn, events.GoldSent, events.GoldReceived, events.TransferDeclined,
+        events.PurchaseRequested, events.PurchaseDeclined:
+        return true
+    }
+    return false
+}
+
+// watch streams bus events accepted by filter as values picked from them,
+// until the subscriber disconnects.
+func watch[T any](ctx context.Context, bus *events.Bus, filter func(events.Event) bool, pick func(events.Event) *T) <-chan *T {
+    in, cancel := bus.Subscribe(func(e events.Event) bool { return filter(e) && pick(e) != nil })
+    out := make(chan *T, 1)
+    go func() {
+        defer close(out)
+        defer cancel()
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case e, ok := <-in:
+                if !ok { return }
+                select {
+                case out <- pick(e):
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }
+    }()
+    return out
+}
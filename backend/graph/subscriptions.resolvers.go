@@ -0,0 +1,43 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/events"
+    "context"
+)
+
+// AssignmentUpdated is the resolver for the assignmentUpdated field.
+func (r *subscriptionResolver) AssignmentUpdated(ctx context.Context, childID string) (<-chan *model.Assignment, error) {
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    return watch(ctx, r.Events,
+        func(e events.Event) bool { return e.ChildID == childID },
+        func(e events.Event) *model.Assignment { return e.Assignment },
+    ), nil
+}
+
+// ChildBalanceChanged is the resolver for the childBalanceChanged field.
+func (r *subscriptionResolver) ChildBalanceChanged(ctx context.Context, parentID string) (<-chan *model.Child, error) {
+    if err := r.authorizeViewer(ctx, parentID); err != nil { return nil, err }
+    return watch(ctx, r.Events,
+        func(e events.Event) bool { return e.ParentID == parentID && changesBalance(e.Type) },
+        func(e events.Event) *model.Child { return e.Child },
+    ), nil
+}
+
+// ApprovalRequested is the resolver for the approvalRequested field.
+func (r *subscriptionResolver) ApprovalRequested(ctx context.Context, parentID string) (<-chan *model.Assignment, error) {
+    if err := r.authorizeViewer(ctx, parentID); err != nil { return nil, err }
+    return watch(ctx, r.Events,
+        func(e events.Event) bool { return e.ParentID == parentID && e.Type == events.AssignmentCompleted },
+        func(e events.Event) *model.Assignment { return e.Assignment },
+    ), nil
+}
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type subscriptionResolver struct{ *Resolver }
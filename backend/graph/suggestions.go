@@ -0,0 +1,17 @@
+package graph
+
+import (
+    "time"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// birthdateFrom validates a YYYY-MM-DD birthdate; nil or empty clears it.
+func birthdateFrom(field string, s *string, now time.Time) (*string, error) {
+    if s == nil || *s == "" { return nil, nil }
+    b, err := time.Parse(time.DateOnly, *s)
+    if err != nil { return nil, apperr.Invalid(field, "must be a date (YYYY-MM-DD)") }
+    if b.After(now) { return nil, apperr.Invalid(field, "must not be in the future") }
+    if b.Before(now.AddDate(-30, 0, 0)) { return nil, apperr.Invalid(field, "must be within the last 30 years") }
+    return s, nil
+}
@@ -0,0 +1,49 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/catalog"
+    "chorequest/backend/internal/suggest"
+    "context"
+    "time"
+)
+
+// SetChildBirthdate is the resolver for the setChildBirthdate field.
+func (r *mutationResolver) SetChildBirthdate(ctx context.Context, childID string, birthdate *string) (*model.Child, error) {
+    if err := r.authorizeChildParent(ctx, childID); err != nil { return nil, err }
+    birthdate, err := birthdateFrom("birthdate", birthdate, time.Now().UTC())
+    if err != nil { return nil, err }
+    return r.Repo.SetChildBirthdate(ctx, childID, birthdate)
+}
+
+// SuggestedQuests is the resolver for the suggestedQuests field.
+func (r *queryResolver) SuggestedQuests(ctx context.Context, childID string, limit *int) ([]*model.QuestSuggestion, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    in := suggest.Input{Now: time.Now().UTC()}
+    if age, ok := model.AgeOn(ch.Birthdate, in.Now); ok { in.Age = &age }
+    own, err := r.Repo.ListQuestTemplates(ctx, ch.ParentID)
+    if err != nil { return nil, err }
+    in.Templates = append(own, catalog.All()...)
+    if in.Quests, err = r.Repo.ListQuests(ctx, ch.ParentID); err != nil { return nil, err }
+    children, err := r.Repo.ListChildren(ctx, ch.ParentID)
+    if err != nil { return nil, err }
+    for _, c := range children {
+        as, err := r.Repo.ListAssignmentsForChild(ctx, c.ID)
+        if err != nil { return nil, err }
+        if c.ID == childID {
+            in.Assignments = as
+        } else {
+            in.Siblings = append(in.Siblings, as)
+        }
+    }
+    n := 10
+    if limit != nil { n = *limit }
+    return suggest.Rank(in, n), nil
+}
@@ -0,0 +1,75 @@
+package graph
+
+import (
+    "context"
+    "crypto/rand"
+    "strings"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/catalog"
+)
+
+const (
+    maxQuestTemplatesPerParent = 100
+    // maxTemplatesPerCall bounds createQuestsFromTemplates and exports.
+    maxTemplatesPerCall = 50
+    templateShareTTL    = 30 * 24 * time.Hour
+)
+
+// exportCodeAlphabet leaves out characters that are easy to misread (0/O, 1/I/L).
+const exportCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// newExportCode returns a code such as "K7QM-2XWD-HP9R".
+func newExportCode() (string, error) {
+    b := make([]byte, 12)
+    if _, err := rand.Read(b); err != nil { return "", err }
+    var sb strings.Builder
+    for i, c := range b {
+        if i > 0 && i%4 == 0 { sb.WriteByte('-') }
+        sb.WriteByte(exportCodeAlphabet[int(c)%len(exportCodeAlphabet)])
+    }
+    return sb.String(), nil
+}
+
+// normalizeExportCode accepts codes typed in lower case or without dashes.
+func normalizeExportCode(code string) string {
+    code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+    if len(code) != 12 { return code }
+    return code[:4] + "-" + code[4:8] + "-" + code[8:]
+}
+
+// questTemplateFrom validates input.
+func questTemplateFrom(in model.NewQuestTemplate) (*model.QuestTemplate, error) {
+    if in.MinAge > in.MaxAge { return nil, apperr.Invalid("input.maxAge", "must be at least minAge") }
+    return &model.QuestTemplate{
+        ParentID: &in.ParentID, Title: in.Title, Description: in.Description,
+        Xp: in.Xp, Gold: in.Gold, MinAge: in.MinAge, MaxAge: in.MaxAge, Category: in.Category,
+    }, nil
+}
+
+// questTemplates resolves ids to built-in templates or parentID's own, in order.
+func (r *Resolver) questTemplates(ctx context.Context, parentID string, ids []string) ([]*model.QuestTemplate, error) {
+    if len(ids) > maxTemplatesPerCall { return nil, apperr.Invalid("templateIds", "at most %d templates", maxTemplatesPerCall) }
+    var own map[string]*model.QuestTemplate
+    out := make([]*model.QuestTemplate, 0, len(ids))
+    for _, id := range ids {
+        if t := catalog.Get(id); t != nil {
+            out = append(out, t)
+            continue
+        }
+        if own == nil {
+            list, err := r.Repo.ListQuestTemplates(ctx, parentID)
+            if err != nil { return nil, err }
+            own = make(map[string]*model.QuestTemplate, len(list))
+            for _, t := range list {
+                own[t.ID] = t
+            }
+        }
+        t := own[id]
+        if t == nil { return nil, apperr.New(apperr.NotFound, "template %s not found", id) }
+        out = append(out, t)
+    }
+    return out, nil
+}
@@ -0,0 +1,100 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/catalog"
+    "context"
+    "time"
+)
+
+// CreateQuestsFromTemplates is the resolver for the createQuestsFromTemplates field.
+func (r *mutationResolver) CreateQuestsFromTemplates(ctx context.Context, parentID string, templateIds []string) ([]*model.Quest, error) {
+    if err := r.authorize(ctx, parentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    ts, err := r.questTemplates(ctx, parentID, templateIds)
+    if err != nil { return nil, err }
+    if err := r.plans().AddQuests(ctx, parentID, len(ts)); err != nil { return nil, err }
+    quests := make([]*model.Quest, 0, len(ts))
+    for _, t := range ts {
+        q, err := r.Repo.CreateQuest(ctx, model.NewQuest{ParentID: parentID, Title: t.Title, Description: t.Description, Xp: t.Xp, Gold: t.Gold, Category: t.Category})
+        if err != nil { return nil, err }
+        quests = append(quests, q)
+    }
+    return quests, nil
+}
+
+// CreateQuestTemplate is the resolver for the createQuestTemplate field.
+func (r *mutationResolver) CreateQuestTemplate(ctx context.Context, input model.NewQuestTemplate) (*model.QuestTemplate, error) {
+    if err := r.authorize(ctx, input.ParentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    t, err := questTemplateFrom(input)
+    if err != nil { return nil, err }
+    existing, err := r.Repo.ListQuestTemplates(ctx, input.ParentID)
+    if err != nil { return nil, err }
+    if len(existing) >= maxQuestTemplatesPerParent { return nil, apperr.Invalid("input", "at most %d templates per household", maxQuestTemplatesPerParent) }
+    return r.Repo.CreateQuestTemplate(ctx, t)
+}
+
+// DeleteQuestTemplate is the resolver for the deleteQuestTemplate field.
+func (r *mutationResolver) DeleteQuestTemplate(ctx context.Context, parentID string, id string) (*model.QuestTemplate, error) {
+    if err := r.authorize(ctx, parentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    return r.Repo.DeleteQuestTemplate(ctx, parentID, id)
+}
+
+// ExportQuestTemplates is the resolver for the exportQuestTemplates field.
+func (r *mutationResolver) ExportQuestTemplates(ctx context.Context, parentID string, templateIds []string) (string, error) {
+    if err := r.authorize(ctx, parentID, model.APIKeyScopeManageQuests); err != nil { return "", err }
+    var ts []*model.QuestTemplate
+    var err error
+    if templateIds == nil {
+        ts, err = r.Repo.ListQuestTemplates(ctx, parentID)
+    } else {
+        ts, err = r.questTemplates(ctx, parentID, templateIds)
+    }
+    if err != nil { return "", err }
+    if len(ts) == 0 { return "", apperr.Invalid("templateIds", "nothing to export") }
+    // The snapshot carries no IDs, so importing never reveals the household.
+    shared := make([]*model.QuestTemplate, len(ts))
+    for i, t := range ts {
+        c := *t
+        c.ID, c.ParentID = "", nil
+        shared[i] = &c
+    }
+    code, err := newExportCode()
+    if err != nil { return "", err }
+    if err := r.Repo.ShareQuestTemplates(ctx, parentID, code, shared, time.Now().Add(templateShareTTL)); err != nil { return "", err }
+    return code, nil
+}
+
+// ImportQuestTemplates is the resolver for the importQuestTemplates field.
+func (r *mutationResolver) ImportQuestTemplates(ctx context.Context, parentID string, code string) ([]*model.QuestTemplate, error) {
+    if err := r.authorize(ctx, parentID, model.APIKeyScopeManageQuests); err != nil { return nil, err }
+    shared, err := r.Repo.SharedQuestTemplates(ctx, normalizeExportCode(code))
+    if err != nil { return nil, err }
+    existing, err := r.Repo.ListQuestTemplates(ctx, parentID)
+    if err != nil { return nil, err }
+    if len(existing)+len(shared) > maxQuestTemplatesPerParent { return nil, apperr.Invalid("code", "at most %d templates per household", maxQuestTemplatesPerParent) }
+    out := make([]*model.QuestTemplate, 0, len(shared))
+    for _, t := range shared {
+        t.ParentID = &parentID
+        created, err := r.Repo.CreateQuestTemplate(ctx, t)
+        if err != nil { return nil, err }
+        out = append(out, created)
+    }
+    return out, nil
+}
+
+// QuestTemplates is the resolver for the questTemplates field.
+func (r *queryResolver) QuestTemplates(ctx context.Context, age *int) ([]*model.QuestTemplate, error) {
+    if age != nil { return catalog.ForAge(*age), nil }
+    return catalog.All(), nil
+}
+
+// MyQuestTemplates is the resolver for the myQuestTemplates field.
+func (r *queryResolver) MyQuestTemplates(ctx context.Context, parentID string) ([]*model.QuestTemplate, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    return r.Repo.ListQuestTemplates(ctx, parentID)
+}
@@ -0,0 +1,89 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "context"
+    "strings"
+)
+
+// SetTransferApprovalThreshold is the resolver for the setTransferApprovalThreshold field.
+func (r *mutationResolver) SetTransferApprovalThreshold(ctx context.Context, parentID string, gold *int) (*int, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    if err := r.Repo.SetTransferThreshold(ctx, parentID, gold); err != nil { return nil, err }
+    return gold, nil
+}
+
+// TransferGold is the resolver for the transferGold field.
+func (r *mutationResolver) TransferGold(ctx context.Context, fromChildID string, toChildID string, amount int, note *string) (*model.GoldTransfer, error) {
+    if err := r.authorizeChild(ctx, fromChildID); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, fromChildID); err != nil { return nil, err }
+    if fromChildID == toChildID { return nil, apperr.Invalid("toChildId", "must differ from fromChildId") }
+    from, err := r.Repo.GetChild(ctx, fromChildID)
+    if err != nil { return nil, err }
+    to, err := r.Repo.GetChild(ctx, toChildID)
+    if err != nil { return nil, err }
+    if to.ParentID != from.ParentID { return nil, apperr.New(apperr.NotFound, "child not found") }
+    plans := r.plans()
+    if err := plans.WriteChild(ctx, from.ParentID, fromChildID); err != nil { return nil, err }
+    if err := plans.WriteChild(ctx, from.ParentID, toChildID); err != nil { return nil, err }
+    if note != nil {
+        if *note = strings.TrimSpace(*note); *note == "" { note = nil }
+    }
+    pending := false
+    if !isHouseholdParent(ctx, from.ParentID) {
+        threshold, err := r.Repo.GetTransferThreshold(ctx, from.ParentID)
+        if err != nil { return nil, err }
+        pending = threshold != nil && amount > *threshold
+    }
+    return r.Repo.TransferGold(ctx, fromChildID, toChildID, amount, note, pending)
+}
+
+// ApproveGoldTransfer is the resolver for the approveGoldTransfer field.
+func (r *mutationResolver) ApproveGoldTransfer(ctx context.Context, parentID string, id string) (*model.GoldTransfer, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.DecideTransfer(ctx, parentID, id, true)
+}
+
+// DeclineGoldTransfer is the resolver for the declineGoldTransfer field.
+func (r *mutationResolver) DeclineGoldTransfer(ctx context.Context, parentID string, id string) (*model.GoldTransfer, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.DecideTransfer(ctx, parentID, id, false)
+}
+
+// TransferApprovalThreshold is the resolver for the transferApprovalThreshold field.
+func (r *queryResolver) TransferApprovalThreshold(ctx context.Context, parentID string) (*int, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    return r.Repo.GetTransferThreshold(ctx, parentID)
+}
+
+// GoldTransfers is the resolver for the goldTransfers field.
+func (r *queryResolver) GoldTransfers(ctx context.Context, parentID string, status *model.TransferStatus) ([]*model.GoldTransfer, error) {
+    if err := r.authorize(ctx, parentID, readScopes...); err != nil { return nil, err }
+    list, err := r.Repo.ListTransfers(ctx, parentID)
+    if err != nil || status == nil { return list, err }
+    out := list[:0]
+    for _, t := range list {
+        if t.Status == *status { out = append(out, t) }
+    }
+    return out, nil
+}
+
+// MyGoldTransfers is the resolver for the myGoldTransfers field.
+func (r *queryResolver) MyGoldTransfers(ctx context.Context, childID string) ([]*model.GoldTransfer, error) {
+    if err := r.authorizeChild(ctx, childID, readScopes...); err != nil { return nil, err }
+    if err := r.authorizeChildViewer(ctx, childID); err != nil { return nil, err }
+    ch, err := r.Repo.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    list, err := r.Repo.ListTransfers(ctx, ch.ParentID)
+    if err != nil { return nil, err }
+    out := list[:0]
+    for _, t := range list {
+        if t.FromChildID == childID || t.ToChildID == childID { out = append(out, t) }
+    }
+    return out, nil
+}
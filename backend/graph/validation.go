@@ -0,0 +1,99 @@
+package graph
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/99designs/gqlgen/graphql"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// NewConfig wires resolvers and schema directives into an executable schema config.
+func NewConfig(r *Resolver) Config {
+    return Config{
+        Resolvers:  r,
+        Directives: DirectiveRoot{Range: Range, Length: Length},
+    }
+}
+
+func invalid(ctx context.Context, format string, args ...any) error {
+    return apperr.Invalid(argPath(ctx), format, args...)
+}
+
+// argPath renders the argument path below the current field, e.g. "input.xp".
+func argPath(ctx context.Context) string {
+    var parts []string
+    for pc := graphql.GetPathContext(ctx); pc != nil; pc = pc.Parent {
+        if pc.Field != nil {
+            parts = append(parts, *pc.Field)
+        } else if pc.Index != nil {
+            parts = append(parts, fmt.Sprint(*pc.Index))
+        }
+    }
+    for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+        parts[i], parts[j] = parts[j], parts[i]
+    }
+    return strings.Join(parts, ".")
+}
+
+// Range implements @range for Int arguments and input fields.
+func Range(ctx context.Context, obj any, next graphql.Resolver, min *int, max *int) (any, error) {
+    v, err := next(ctx)
+    if err != nil { return v, err }
+    var n int
+    switch t := v.(type) {
+    case int:
+        n = t
+    case *int:
+        if t == nil { return v, nil }
+        n = *t
+    default:
+        return v, nil
+    }
+    if min != nil && n < *min {
+        if *min == 0 { return v, invalid(ctx, "must not be negative") }
+        return v, invalid(ctx, "must be at least %d", *min)
+    }
+    if max != nil && n > *max { return v, invalid(ctx, "must be at most %d", *max) }
+    return v, nil
+}
+
+// Length implements @length for String arguments and input fields. It trims
+// surrounding whitespace and passes the trimmed value on, so the length
+// checked in characters is the length resolvers store.
+func Length(ctx context.Context, obj any, next graphql.Resolver, min *int, max *int) (any, error) {
+    v, err := next(ctx)
+    if err != nil { return v, err }
+    var s string
+    switch t := v.(type) {
+    case string:
+        s = strings.TrimSpace(t)
+        v = s
+    case *string:
+        if t == nil { return v, nil }
+        s = strings.TrimSpace(*t)
+        v = &s
+    default:
+        return v, nil
+    }
+    n := utf8.RuneCountInString(s)
+    if min != nil && n < *min {
+        if *min == 1 { return v, invalid(ctx, "must not be empty") }
+        return v, invalid(ctx, "must be at least %d characters", *min)
+    }
+    if max != nil && n > *max { return v, invalid(ctx, "must be at most %d characters", *max) }
+    return v, nil
+}
+
+// validateURL checks that raw is an absolute http(s) URL.
+func validateURL(field, raw string) error {
+    u, err := url.Parse(raw)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        return apperr.Invalid(field, "must be an absolute http(s) URL")
+    }
+    return nil
+}
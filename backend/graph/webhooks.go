@@ -0,0 +1,29 @@
+package graph
+
+import (
+    "net/netip"
+    "net/url"
+    "strings"
+
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/outbound"
+)
+
+const maxWebhooksPerParent = 10
+
+// validateOutboundURL checks a URL the server will POST to, such as a webhook
+// receiver or push endpoint. Outside dev only https is accepted and literal
+// non-public addresses are refused up front; hostnames are checked again when
+// outbound clients connect, since they may resolve to internal services.
+func (r *Resolver) validateOutboundURL(field, raw string) error {
+    if err := validateURL(field, raw); err != nil { return err }
+    if r.Config == nil || r.Config.DevToolsEnabled() { return nil }
+    u, _ := url.Parse(raw)
+    if u.Scheme != "https" { return apperr.Invalid(field, "must use https") }
+    host := strings.ToLower(u.Hostname())
+    if host == "localhost" || strings.HasSuffix(host, ".localhost") { return apperr.Invalid(field, "must be a public address") }
+    if ip, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil && !outbound.Public(ip) {
+        return apperr.Invalid(field, "must be a public address")
+    }
+    return nil
+}
@@ -0,0 +1,50 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/webhook"
+    "context"
+)
+
+// RegisterWebhook is the resolver for the registerWebhook field.
+func (r *mutationResolver) RegisterWebhook(ctx context.Context, parentID string, url string, events []model.WebhookEvent) (*model.RegisteredWebhook, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    if err := r.validateOutboundURL("url", url); err != nil { return nil, err }
+    if len(events) == 0 { return nil, apperr.Invalid("events", "at least one event is required") }
+    existing, err := r.Repo.ListWebhooks(ctx, parentID)
+    if err != nil { return nil, err }
+    if len(existing) >= maxWebhooksPerParent { return nil, apperr.New(apperr.Validation, "at most %d webhooks per household", maxWebhooksPerParent) }
+    secret, err := webhook.GenerateSecret()
+    if err != nil { return nil, err }
+    w, err := r.Repo.CreateWebhook(ctx, parentID, url, secret, events)
+    if err != nil { return nil, err }
+    return &model.RegisteredWebhook{Webhook: w, Secret: secret}, nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, parentID string, id string) (*model.Webhook, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.DeleteWebhook(ctx, parentID, id)
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context, parentID string) ([]*model.Webhook, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    return r.Repo.ListWebhooks(ctx, parentID)
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, parentID string, webhookID string, limit *int) ([]*model.WebhookDelivery, error) {
+    if err := r.authorizeParent(ctx, parentID); err != nil { return nil, err }
+    w, _, err := r.Repo.GetWebhook(ctx, webhookID)
+    if err != nil { return nil, err }
+    if w.ParentID != parentID { return nil, apperr.New(apperr.NotFound, "webhook not found") }
+    n := 50
+    if limit != nil { n = *limit }
+    return r.Repo.ListWebhookDeliveries(ctx, webhookID, n)
+}
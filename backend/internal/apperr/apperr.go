@@ -0,0 +1,71 @@
+// Package apperr defines typed domain errors that the GraphQL layer exposes to
+// clients as extensions.code.
+package apperr
+
+import (
+    "errors"
+    "fmt"
+)
+
+// Code is a stable, client-facing error classification.
+type Code string
+
+const (
+    NotFound          Code = "NOT_FOUND"
+    InsufficientFunds Code = "INSUFFICIENT_FUNDS"
+    AlreadyCompleted  Code = "ALREADY_COMPLETED"
+    Forbidden         Code = "FORBIDDEN"
+    Validation        Code = "VALIDATION"
+    Conflict          Code = "CONFLICT"
+    RateLimited       Code = "RATE_LIMITED"
+    PlanLimit         Code = "PLAN_LIMIT"
+    Internal          Code = "INTERNAL"
+)
+
+// Error carries a Code and a message that is safe to show to end users. Field
+// names the offending input for VALIDATION errors. The wrapped Err, if any, is
+// for logs only.
+type Error struct {
+    Code    Code
+    Message string
+    Field   string
+    Err     error
+}
+
+func (e *Error) Error() string {
+    if e.Err != nil {
+        return e.Message + ": " + e.Err.Error()
+    }
+    return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns an Error with a formatted message.
+func New(code Code, format string, args ...any) *Error {
+    return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid returns a VALIDATION error for the named input field.
+func Invalid(field, format string, args ...any) *Error {
+    return &Error{Code: Validation, Message: fmt.Sprintf(format, args...), Field: field}
+}
+
+// Wrap attaches code and a user-facing message to an underlying error.
+func Wrap(code Code, err error, message string) *Error {
+    return &Error{Code: code, Message: message, Err: err}
+}
+
+// CodeOf returns the Code of the first *Error in err's chain, or Internal.
+func CodeOf(err error) Code {
+    var e *Error
+    if errors.As(err, &e) {
+        return e.Code
+    }
+    return Internal
+}
+
+// Is reports whether err carries code.
+func Is(err error, code Code) bool {
+    return err != nil && CodeOf(err) == code
+}
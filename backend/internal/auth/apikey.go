@@ -0,0 +1,93 @@
+package auth
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "net/http"
+    "strings"
+
+    "chorequest/backend/graph/model"
+)
+
+const scopesKey ctxKey = "scopes"
+
+// APIKeyPrefix marks plaintext keys so they are easy to spot in configs and logs.
+const APIKeyPrefix = "cq_"
+
+// APIKeyStore resolves hashed API keys; implemented by repo.Repo.
+type APIKeyStore interface {
+    GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+    TouchAPIKey(ctx context.Context, parentID, keyID string) error
+}
+
+// GenerateAPIKey returns a new plaintext key, its short display prefix and the hash to persist.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+    b := make([]byte, 32)
+    if _, err = rand.Read(b); err != nil {
+        return "", "", "", err
+    }
+    key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+    return key, key[:len(APIKeyPrefix)+6], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of a plaintext key. Keys carry 256 bits of
+// entropy, so an unsalted fast hash is sufficient for lookup.
+func HashAPIKey(key string) string {
+    sum := sha256.Sum256([]byte(key))
+    return hex.EncodeToString(sum[:])
+}
+
+// APIKeyMiddleware authenticates requests carrying an API key in the X-API-Key
+// header or as "Authorization: ApiKey <key>". The owning parent becomes the
+// subject and the key's scopes are attached to the context. Requests without a
+// key pass through untouched; unknown or revoked keys are rejected with 401.
+func APIKeyMiddleware(store APIKeyStore) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        if store == nil {
+            return next
+        }
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            key := apiKeyFromRequest(r)
+            if key == "" {
+                next.ServeHTTP(w, r)
+                return
+            }
+            k, err := store.GetAPIKeyByHash(r.Context(), HashAPIKey(key))
+            if err != nil || k == nil || k.RevokedAt != nil {
+                http.Error(w, "invalid api key", http.StatusUnauthorized)
+                return
+            }
+            // Last-used tracking is best effort and must not fail the request.
+            _ = store.TouchAPIKey(r.Context(), k.ParentID, k.ID)
+            scopes := make([]string, 0, len(k.Scopes))
+            for _, s := range k.Scopes {
+                scopes = append(scopes, string(s))
+            }
+            ctx := context.WithValue(r.Context(), subjectKey, k.ParentID)
+            ctx = context.WithValue(ctx, roleKey, string(model.RoleParent))
+            ctx = context.WithValue(ctx, scopesKey, scopes)
+            next.ServeHTTP(w, r.WithContext(ctx))
+        })
+    }
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+    if v := strings.TrimSpace(r.Header.Get("X-API-Key")); v != "" {
+        return v
+    }
+    authz := r.Header.Get("Authorization")
+    if strings.HasPrefix(strings.ToLower(authz), "apikey ") {
+        return strings.TrimSpace(authz[len("ApiKey "):])
+    }
+    return ""
+}
+
+// ScopesFromContext returns the API key scopes of the caller. ok is false when
+// the request was not authenticated with an API key (JWT callers are unscoped).
+func ScopesFromContext(ctx context.Context) (scopes []string, ok bool) {
+    scopes, ok = ctx.Value(scopesKey).([]string)
+    return scopes, ok
+}
@@ -21,35 +21,45 @@ func JWTMiddleware(secret string) func(http.Handler) http.Handler {
             return next
         }
         return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-            authz := r.Header.Get("Authorization")
-            if authz == "" || !strings.HasPrefix(strings.ToLower(authz), "bearer ") {
-                next.ServeHTTP(w, r)
-                return
-            }
-            tokenString := strings.TrimSpace(authz[len("Bearer "):])
-            token, err := jwt.Parse(tokenString, func(t *jwt.Token) (any, error) {
-                if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-                    return nil, jwt.ErrInvalidKeyType
-                }
-                return []byte(secret), nil
-            })
-            if err == nil && token != nil && token.Valid {
-                if claims, ok := token.Claims.(jwt.MapClaims); ok {
-                    if sub, ok := claims["sub"].(string); ok {
-                        ctx := context.WithValue(r.Context(), subjectKey, sub)
-                        // attach role if present
-                        if role, ok := claims["role"].(string); ok {
-                            ctx = context.WithValue(ctx, roleKey, role)
-                        }
-                        r = r.WithContext(ctx)
-                    }
-                }
+            if ctx, ok := WithBearer(r.Context(), secret, r.Header.Get("Authorization")); ok {
+                r = r.WithContext(ctx)
             }
             next.ServeHTTP(w, r)
         })
     }
 }
 
+// WithBearer validates an "Authorization: Bearer" value and, when valid, returns
+// ctx carrying the token's subject and role. It is shared by the HTTP middleware
+// and the GraphQL WebSocket init handshake.
+func WithBearer(ctx context.Context, secret, authz string) (context.Context, bool) {
+    if secret == "" || authz == "" || !strings.HasPrefix(strings.ToLower(authz), "bearer ") {
+        return ctx, false
+    }
+    tokenString := strings.TrimSpace(authz[len("Bearer "):])
+    // Tokens without exp predate expiring logins and would otherwise outlive
+    // any later change such as enabling MFA.
+    token, err := jwt.Parse(tokenString, hmacKey(secret), jwt.WithExpirationRequired())
+    if err != nil || token == nil || !token.Valid {
+        return ctx, false
+    }
+    // MFA-pending tokens only prove the first factor and grant nothing here.
+    claims, ok := token.Claims.(jwt.MapClaims)
+    if !ok || claims["mfa"] != nil {
+        return ctx, false
+    }
+    sub, ok := claims["sub"].(string)
+    if !ok {
+        return ctx, false
+    }
+    ctx = context.WithValue(ctx, subjectKey, sub)
+    // attach role if present
+    if role, ok := claims["role"].(string); ok {
+        ctx = context.WithValue(ctx, roleKey, role)
+    }
+    return ctx, true
+}
+
 func SubjectFromContext(ctx context.Context) string {
     v, _ := ctx.Value(subjectKey).(string)
     return v
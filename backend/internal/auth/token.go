@@ -0,0 +1,56 @@
+package auth
+
+import (
+    "errors"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// MFAPendingTTL bounds how long a parent has to enter their second factor.
+const MFAPendingTTL = 5 * time.Minute
+
+const mfaPending = "pending"
+
+// IssueToken signs an HS256 JWT for sub with the given role that expires after
+// ttl. WithBearer rejects tokens without an expiry, so ttl must be positive.
+func IssueToken(secret, sub, role string, ttl time.Duration) (string, error) {
+    if ttl <= 0 { return "", errors.New("token ttl must be positive") }
+    now := time.Now()
+    claims := jwt.MapClaims{"sub": sub, "role": role, "iat": now.Unix(), "exp": now.Add(ttl).Unix()}
+    return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// IssueMFAPendingToken signs a short-lived token that only proves the first
+// factor. JWTMiddleware ignores it; it can only be exchanged at the MFA step.
+func IssueMFAPendingToken(secret, sub string) (string, error) {
+    now := time.Now()
+    claims := jwt.MapClaims{"sub": sub, "mfa": mfaPending, "iat": now.Unix(), "exp": now.Add(MFAPendingTTL).Unix()}
+    return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// ParseMFAPendingToken validates a pending token and returns its subject.
+func ParseMFAPendingToken(secret, tokenString string) (string, error) {
+    claims := jwt.MapClaims{}
+    token, err := jwt.ParseWithClaims(tokenString, claims, hmacKey(secret), jwt.WithExpirationRequired())
+    if err != nil || !token.Valid {
+        return "", errors.New("invalid or expired mfa token")
+    }
+    if claims["mfa"] != mfaPending {
+        return "", errors.New("not an mfa token")
+    }
+    sub, _ := claims["sub"].(string)
+    if sub == "" {
+        return "", errors.New("mfa token missing subject")
+    }
+    return sub, nil
+}
+
+func hmacKey(secret string) jwt.Keyfunc {
+    return func(t *jwt.Token) (any, error) {
+        if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+            return nil, jwt.ErrInvalidKeyType
+        }
+        return []byte(secret), nil
+    }
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha1"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/base32"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// TOTP parameters (RFC 6238 defaults understood by all authenticator apps).
+const (
+    totpPeriod = 30
+    totpDigits = 6
+    totpSkew   = 1 // accept one step either side for clock drift
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random 160-bit secret, base32 encoded.
+func GenerateTOTPSecret() (string, error) {
+    b := make([]byte, 20)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return b32.EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI builds the otpauth:// URI rendered as a QR code by clients.
+func TOTPProvisioningURI(issuer, account, secret string) string {
+    v := url.Values{}
+    v.Set("secret", secret)
+    v.Set("issuer", issuer)
+    v.Set("algorithm", "SHA1")
+    v.Set("digits", fmt.Sprintf("%d", totpDigits))
+    v.Set("period", fmt.Sprintf("%d", totpPeriod))
+    label := url.PathEscape(issuer + ":" + account)
+    return "otpauth://totp/" + label + "?" + v.Encode()
+}
+
+// ValidateTOTP checks code against secret at time t. It returns the matched
+// time step so callers can reject replays of the same code.
+func ValidateTOTP(secret, code string, t time.Time) (step int64, ok bool) {
+    key, err := b32.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+    if err != nil {
+        return 0, false
+    }
+    code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+    if len(code) != totpDigits {
+        return 0, false
+    }
+    now := t.Unix() / totpPeriod
+    for s := now - totpSkew; s <= now+totpSkew; s++ {
+        if subtle.ConstantTimeCompare([]byte(totpCode(key, s)), []byte(code)) == 1 {
+            return s, true
+        }
+    }
+    return 0, false
+}
+
+func totpCode(key []byte, step int64) string {
+    var msg [8]byte
+    binary.BigEndian.PutUint64(msg[:], uint64(step))
+    mac := hmac.New(sha1.New, key)
+    mac.Write(msg[:])
+    sum := mac.Sum(nil)
+    off := sum[len(sum)-1] & 0x0f
+    v := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+    return fmt.Sprintf("%0*d", totpDigits, v%1000000)
+}
+
+// GenerateRecoveryCodes returns n one-time codes formatted as xxxxx-xxxxx.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+    codes := make([]string, 0, n)
+    for i := 0; i < n; i++ {
+        b := make([]byte, 7)
+        if _, err := rand.Read(b); err != nil {
+            return nil, err
+        }
+        s := strings.ToLower(b32.EncodeToString(b))[:10]
+        codes = append(codes, s[:5]+"-"+s[5:])
+    }
+    return codes, nil
+}
+
+// HashRecoveryCode normalizes and hashes a recovery code for storage.
+func HashRecoveryCode(code string) string {
+    c := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+    sum := sha256.Sum256([]byte(c))
+    return hex.EncodeToString(sum[:])
+}
+
+// MFAStore records consumed second factors; implemented by repo.Repo.
+type MFAStore interface {
+    UseTOTPStep(ctx context.Context, parentID string, step int64) error
+    UseRecoveryCode(ctx context.Context, parentID, hash string) error
+}
+
+// VerifySecondFactor accepts either a current TOTP code or an unused recovery
+// code for parentID, consuming it so it cannot be presented again.
+func VerifySecondFactor(ctx context.Context, store MFAStore, parentID, secret, code string) bool {
+    if step, ok := ValidateTOTP(secret, code, time.Now()); ok {
+        return store.UseTOTPStep(ctx, parentID, step) == nil
+    }
+    return store.UseRecoveryCode(ctx, parentID, HashRecoveryCode(code)) == nil
+}
@@ -0,0 +1,103 @@
+// Package billing keeps each parent's premium subscription in sync with Stripe.
+package billing
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "log"
+    "net/http"
+    "time"
+
+    stripe "github.com/stripe/stripe-go/v76"
+    stripewebhook "github.com/stripe/stripe-go/v76/webhook"
+
+    "chorequest/backend/internal/apperr"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// ParentMetadataKey is set on checkout subscriptions so subscription events
+// identify the parent even if they arrive before checkout.session.completed.
+const ParentMetadataKey = "parentId"
+
+// maxPayload bounds webhook bodies; Stripe events are well below it.
+const maxPayload = 1 << 20
+
+// WebhookHandler serves /webhooks/stripe. Requests must carry a valid
+// Stripe-Signature for secret. Handled events answer 200; failures to store
+// answer 500 so Stripe retries. Unknown event types are acknowledged.
+type WebhookHandler struct {
+    Store  repopkg.Repo
+    Secret string
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if h.Secret == "" {
+        http.Error(w, "stripe webhooks not configured", http.StatusServiceUnavailable)
+        return
+    }
+    payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayload))
+    if err != nil { http.Error(w, "payload too large", http.StatusRequestEntityTooLarge); return }
+    ev, err := stripewebhook.ConstructEventWithOptions(payload, r.Header.Get("Stripe-Signature"), h.Secret,
+        stripewebhook.ConstructEventOptions{IgnoreAPIVersionMismatch: true})
+    if err != nil { http.Error(w, "invalid signature", http.StatusBadRequest); return }
+    if err := h.handle(r.Context(), ev); err != nil {
+        if apperr.Is(err, apperr.NotFound) || apperr.Is(err, apperr.Validation) {
+            // Not ours (e.g. a customer created outside checkout); retrying will not help.
+            log.Printf("billing: ignoring %s %s: %v", ev.Type, ev.ID, err)
+            w.WriteHeader(http.StatusOK)
+            return
+        }
+        log.Printf("billing: %s %s: %v", ev.Type, ev.ID, err)
+        http.Error(w, "failed to process event", http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) handle(ctx context.Context, ev stripe.Event) error {
+    at := time.Unix(ev.Created, 0)
+    switch ev.Type {
+    case "checkout.session.completed":
+        var s stripe.CheckoutSession
+        if err := json.Unmarshal(ev.Data.Raw, &s); err != nil { return err }
+        if s.Mode != stripe.CheckoutSessionModeSubscription { return nil }
+        if s.ClientReferenceID == "" || s.Customer == nil { return apperr.New(apperr.Validation, "checkout session without parent or customer") }
+        var sub string
+        if s.Subscription != nil { sub = s.Subscription.ID }
+        return h.Store.LinkCustomer(ctx, s.ClientReferenceID, s.Customer.ID, sub)
+
+    case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+        var s stripe.Subscription
+        if err := json.Unmarshal(ev.Data.Raw, &s); err != nil { return err }
+        if s.Customer == nil { return apperr.New(apperr.Validation, "subscription without customer") }
+        parentID, err := h.parentOf(ctx, s.Metadata, s.Customer.ID)
+        if err != nil { return err }
+        b := repopkg.Billing{CustomerID: s.Customer.ID, SubscriptionID: s.ID, Status: string(s.Status), CancelAtPeriodEnd: s.CancelAtPeriodEnd}
+        if s.CurrentPeriodEnd > 0 {
+            end := time.Unix(s.CurrentPeriodEnd, 0).UTC().Format(time.RFC3339)
+            b.CurrentPeriodEnd = &end
+        }
+        _, err = h.Store.ApplySubscription(ctx, parentID, b, at)
+        return err
+
+    case "invoice.payment_failed":
+        var inv stripe.Invoice
+        if err := json.Unmarshal(ev.Data.Raw, &inv); err != nil { return err }
+        if inv.Subscription == nil || inv.Customer == nil { return nil }
+        var metadata map[string]string
+        if inv.SubscriptionDetails != nil { metadata = inv.SubscriptionDetails.Metadata }
+        parentID, err := h.parentOf(ctx, metadata, inv.Customer.ID)
+        if err != nil { return err }
+        _, err = h.Store.RecordPaymentFailure(ctx, parentID, at)
+        return err
+    }
+    return nil
+}
+
+// parentOf prefers the parent recorded in subscription metadata and falls
+// back to the customer link made at checkout.
+func (h *WebhookHandler) parentOf(ctx context.Context, metadata map[string]string, customerID string) (string, error) {
+    if p := metadata[ParentMetadataKey]; p != "" { return p, nil }
+    return h.Store.ParentForCustomer(ctx, customerID)
+}
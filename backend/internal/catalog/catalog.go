@@ -0,0 +1,42 @@
+// Package catalog is the built-in library of quest templates. The templates
+// are embedded in the binary from catalog.json and parsed once at startup.
+package catalog
+
+import (
+    _ "embed"
+    "encoding/json"
+    "fmt"
+
+    "chorequest/backend/graph/model"
+)
+
+//go:embed catalog.json
+var catalogJSON []byte
+
+var templates, byID = load()
+
+func load() ([]*model.QuestTemplate, map[string]*model.QuestTemplate) {
+    var ts []*model.QuestTemplate
+    if err := json.Unmarshal(catalogJSON, &ts); err != nil { panic(fmt.Sprintf("catalog: %v", err)) }
+    ids := make(map[string]*model.QuestTemplate, len(ts))
+    for _, t := range ts {
+        if t.ID == "" || ids[t.ID] != nil || t.MinAge > t.MaxAge { panic(fmt.Sprintf("catalog: bad template %q", t.ID)) }
+        ids[t.ID] = t
+    }
+    return ts, ids
+}
+
+// All returns the catalog in its file order. Callers must not modify the templates.
+func All() []*model.QuestTemplate { return templates }
+
+// Get returns the template with id, or nil.
+func Get(id string) *model.QuestTemplate { return byID[id] }
+
+// ForAge returns the templates suitable for a child of age.
+func ForAge(age int) []*model.QuestTemplate {
+    out := []*model.QuestTemplate{}
+    for _, t := range templates {
+        if t.MinAge <= age && age <= t.MaxAge { out = append(out, t) }
+    }
+    return out
+}
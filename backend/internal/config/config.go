@@ -0,0 +1,234 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "net/mail"
+    "net/netip"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Env is the deployment mode the server runs in.
+type Env string
+
+const (
+    EnvDev     Env = "dev"
+    EnvStaging Env = "staging"
+    EnvProd    Env = "prod"
+)
+
+// Config holds server settings resolved from the environment.
+type Config struct {
+    Env             Env
+    Port            string
+    JWTSecret       string
+    // Lifetime of issued login tokens
+    JWTTTL          time.Duration
+    TableName       string
+    AutoMigrate     bool
+    EnableGraphiQL  bool
+    CORSOrigins     []string
+    CORSCredentials bool
+
+    // GraphQL hardening
+    ComplexityLimit int
+    DepthLimit      int
+    AllowlistFile   string
+    AllowlistOnly   bool
+
+    // Rate limits in requests per minute per caller (JWT subject or IP); 0 disables
+    RateLimitRequests  int
+    RateLimitAuth      int
+    RateLimitMutations int
+    // Failed API-key and JWT checks per client IP
+    RateLimitAuthFailures int
+    // Proxies whose X-Forwarded-For/X-Real-IP headers are trusted for the client IP
+    TrustedProxies []netip.Prefix
+
+    // Outgoing email; without SMTPAddr messages are only logged
+    SMTPAddr     string
+    SMTPFrom     string
+    SMTPUsername string
+    SMTPPassword string
+
+    // Web Push (VAPID) keys from `go run ./cmd/push-service -gen-vapid`; without
+    // them dev uses a throwaway pair and prod disables push
+    VAPIDPublicKey  string
+    VAPIDPrivateKey string
+    VAPIDSubject    string
+
+    // Stripe API key and subscription price; without the key checkout and the
+    // billing portal return placeholder URLs
+    StripeSecret    string
+    StripePriceID   string
+    StripeTrialDays int
+    // Stripe webhook signing secret (whsec_...); /webhooks/stripe answers 503 without it
+    StripeWebhookSecret string
+
+    // Free plan caps; 0 means unlimited. Premium households are never capped.
+    FreeMaxChildren        int
+    FreeMaxActiveQuests    int
+    FreeRecurringSchedules bool
+}
+
+// defaultCORSOrigins matches the Vite dev server; "*" keeps ad-hoc clients working locally.
+var defaultCORSOrigins = []string{"http://localhost:5173", "http://127.0.0.1:5173", "*"}
+
+// Load reads configuration from environment variables. APP_ENV selects the mode
+// (dev, staging or prod) and must be set, so a forgotten variable never fails open. CORS_ALLOWED_ORIGINS is a comma-separated list.
+func Load() (*Config, error) {
+    env, err := parseEnv(os.Getenv("APP_ENV"))
+    if err != nil { return nil, err }
+    c := &Config{
+        Env:             env,
+        Port:            os.Getenv("PORT"),
+        JWTSecret:       os.Getenv("JWT_SECRET"),
+        TableName:       os.Getenv("DYNAMO_TABLE_NAME"),
+        AutoMigrate:     os.Getenv("DYNAMO_AUTO_MIGRATE") == "1",
+        EnableGraphiQL:  os.Getenv("ENABLE_GRAPHIQL") == "1",
+        CORSOrigins:     splitList(os.Getenv("CORS_ALLOWED_ORIGINS")),
+        CORSCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") != "0",
+        AllowlistFile:   os.Getenv("GRAPHQL_ALLOWLIST_FILE"),
+        AllowlistOnly:   os.Getenv("GRAPHQL_ALLOWLIST_ONLY") == "1",
+        SMTPAddr:        os.Getenv("SMTP_ADDR"),
+        SMTPFrom:        os.Getenv("SMTP_FROM"),
+        SMTPUsername:    os.Getenv("SMTP_USERNAME"),
+        SMTPPassword:    os.Getenv("SMTP_PASSWORD"),
+        VAPIDPublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+        VAPIDPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+        VAPIDSubject:    os.Getenv("VAPID_SUBJECT"),
+        StripeSecret:    os.Getenv("STRIPE_SECRET"),
+        StripePriceID:   os.Getenv("STRIPE_PRICE_ID"),
+        StripeWebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+        FreeRecurringSchedules: os.Getenv("FREE_RECURRING_SCHEDULES") == "1",
+    }
+    if c.JWTTTL, err = durationEnv("JWT_TTL", 12*time.Hour); err != nil { return nil, err }
+    if c.ComplexityLimit, err = intEnv("GRAPHQL_COMPLEXITY_LIMIT", 500); err != nil { return nil, err }
+    if c.DepthLimit, err = intEnv("GRAPHQL_DEPTH_LIMIT", 12); err != nil { return nil, err }
+    if c.RateLimitRequests, err = intEnv("RATE_LIMIT_REQUESTS_PER_MIN", 600); err != nil { return nil, err }
+    if c.RateLimitAuth, err = intEnv("RATE_LIMIT_AUTH_PER_MIN", 10); err != nil { return nil, err }
+    if c.RateLimitMutations, err = intEnv("RATE_LIMIT_MUTATIONS_PER_MIN", 120); err != nil { return nil, err }
+    if c.RateLimitAuthFailures, err = intEnv("RATE_LIMIT_AUTH_FAILURES_PER_MIN", 20); err != nil { return nil, err }
+    if c.TrustedProxies, err = prefixList("TRUSTED_PROXIES"); err != nil { return nil, err }
+    if c.StripeTrialDays, err = intEnv("STRIPE_TRIAL_DAYS", 0); err != nil { return nil, err }
+    if c.FreeMaxChildren, err = intEnv("FREE_MAX_CHILDREN", 2); err != nil { return nil, err }
+    if c.FreeMaxActiveQuests, err = intEnv("FREE_MAX_ACTIVE_QUESTS", 10); err != nil { return nil, err }
+    if c.Port == "" { c.Port = "8080" }
+    if len(c.CORSOrigins) == 0 { c.CORSOrigins = defaultCORSOrigins }
+    if err := c.Validate(); err != nil { return nil, err }
+    return c, nil
+}
+
+// Validate rejects insecure combinations when running in prod.
+func (c *Config) Validate() error {
+    var errs []error
+    if c.AllowlistOnly && c.AllowlistFile == "" {
+        errs = append(errs, errors.New("GRAPHQL_ALLOWLIST_ONLY requires GRAPHQL_ALLOWLIST_FILE"))
+    }
+    if c.SMTPAddr != "" && c.SMTPFrom == "" {
+        errs = append(errs, errors.New("SMTP_ADDR requires SMTP_FROM"))
+    }
+    if c.SMTPFrom != "" {
+        if _, err := mail.ParseAddress(c.SMTPFrom); err != nil {
+            errs = append(errs, fmt.Errorf("SMTP_FROM must be an address such as \"ChoreQuest <noreply@example.com>\": %v", err))
+        }
+    }
+    if c.StripeSecret != "" && c.StripePriceID == "" {
+        errs = append(errs, errors.New("STRIPE_SECRET requires STRIPE_PRICE_ID"))
+    }
+    if c.StripeTrialDays > 730 {
+        errs = append(errs, errors.New("STRIPE_TRIAL_DAYS must be at most 730"))
+    }
+    if c.VAPIDPublicKey != "" && c.VAPIDPrivateKey == "" {
+        errs = append(errs, errors.New("VAPID_PUBLIC_KEY requires VAPID_PRIVATE_KEY"))
+    }
+    if c.Env != EnvProd {
+        return errors.Join(errs...)
+    }
+    if c.JWTSecret == "" {
+        errs = append(errs, errors.New("JWT_SECRET must be set in prod"))
+    }
+    if c.VAPIDPrivateKey != "" && c.VAPIDSubject == "" {
+        errs = append(errs, errors.New("VAPID_SUBJECT must be set in prod when push is enabled"))
+    }
+    if c.CORSCredentials {
+        for _, o := range c.CORSOrigins {
+            if o == "*" {
+                errs = append(errs, errors.New("wildcard CORS origin cannot be combined with credentials in prod"))
+                break
+            }
+        }
+    }
+    return errors.Join(errs...)
+}
+
+// DevToolsEnabled reports whether /auth/dev, /play and /graphiql may be mounted.
+func (c *Config) DevToolsEnabled() bool { return c.Env == EnvDev }
+
+func parseEnv(v string) (Env, error) {
+    switch strings.ToLower(strings.TrimSpace(v)) {
+    case "":
+        return "", errors.New("APP_ENV must be set (dev, staging or prod)")
+    case "dev", "development", "local":
+        return EnvDev, nil
+    case "staging", "stage":
+        return EnvStaging, nil
+    case "prod", "production":
+        return EnvProd, nil
+    }
+    return "", fmt.Errorf("unknown APP_ENV %q (want dev, staging or prod)", v)
+}
+
+// intEnv parses a non-negative integer variable; 0 disables the related limit.
+func intEnv(name string, def int) (int, error) {
+    v := strings.TrimSpace(os.Getenv(name))
+    if v == "" {
+        return def, nil
+    }
+    n, err := strconv.Atoi(v)
+    if err != nil || n < 0 {
+        return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, v)
+    }
+    return n, nil
+}
+
+// durationEnv parses a positive Go duration such as "12h" or "30m".
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+    v := strings.TrimSpace(os.Getenv(name))
+    if v == "" {
+        return def, nil
+    }
+    d, err := time.ParseDuration(v)
+    if err != nil || d <= 0 {
+        return 0, fmt.Errorf("%s must be a positive duration such as 12h, got %q", name, v)
+    }
+    return d, nil
+}
+
+func splitList(v string) []string {
+    var out []string
+    for _, s := range strings.Split(v, ",") {
+        if s = strings.TrimSpace(s); s != "" {
+            out = append(out, s)
+        }
+    }
+    return out
+}
+
+// prefixList parses a comma-separated list of CIDRs or single IP addresses.
+func prefixList(name string) ([]netip.Prefix, error) {
+    var out []netip.Prefix
+    for _, v := range splitList(os.Getenv(name)) {
+        p, err := netip.ParsePrefix(v)
+        if err != nil {
+            a, aerr := netip.ParseAddr(v)
+            if aerr != nil { return nil, fmt.Errorf("%s: %q is not an IP address or CIDR", name, v) }
+            p = netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen())
+        }
+        out = append(out, p.Masked())
+    }
+    return out, nil
+}
@@ -12,7 +12,7 @@ import (
 )
 
 // EnsureSingleTable creates a generic single-table model suitable for a wide range of entities.
-// Keys: PK, SK (both strings). GSIs: GSI1(PK/SK), GSI2(PK/SK)
+// Keys: PK, SK (both strings). GSIs: GSI1(PK/SK), GSI2(PK/SK). TTL: ExpiresAt
 // Table name is env DYNAMO_TABLE_NAME or provided name (fallback: chorequest)
 func EnsureSingleTable(ctx context.Context, c *Client, name string) error {
     if name == "" {
@@ -25,7 +25,7 @@ func EnsureSingleTable(ctx context.Context, c *Client, name string) error {
     // Check if table exists
     _, err := c.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
     if err == nil {
-        return nil
+        return ensureTTL(ctx, c, name)
     }
 
     // Create
@@ -71,10 +71,30 @@ func EnsureSingleTable(ctx context.Context, c *Client, name string) error {
     for i := 0; i < 30; i++ {
         out, err := c.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
         if err == nil && out.Table != nil && out.Table.TableStatus == types.TableStatusActive {
-            return nil
+            return ensureTTL(ctx, c, name)
         }
         time.Sleep(2 * time.Second)
     }
     return fmt.Errorf("table %s not active in time", name)
 }
 
+// ensureTTL enables expiry on the ExpiresAt attribute (epoch seconds), used by
+// short-lived items such as idempotency records.
+func ensureTTL(ctx context.Context, c *Client, name string) error {
+    out, err := c.Dynamo.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(name)})
+    if err == nil && out.TimeToLiveDescription != nil && out.TimeToLiveDescription.TimeToLiveStatus == types.TimeToLiveStatusEnabled {
+        return nil
+    }
+    _, err = c.Dynamo.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+        TableName: aws.String(name),
+        TimeToLiveSpecification: &types.TimeToLiveSpecification{
+            AttributeName: aws.String("ExpiresAt"),
+            Enabled:       aws.Bool(true),
+        },
+    })
+    if err != nil {
+        return fmt.Errorf("enable ttl: %w", err)
+    }
+    return nil
+}
+
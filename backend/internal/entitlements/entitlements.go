@@ -0,0 +1,189 @@
+// Package entitlements decides what a household's plan allows. Premium (an
+// active Stripe subscription) is unlimited; the free plan is capped by Limits
+// from config. Nothing is deleted when a household falls back to free: the
+// oldest children and quests within the caps stay usable and the rest become
+// read-only until the household upgrades or makes room.
+package entitlements
+
+import (
+    "context"
+    "sort"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/config"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// Limits caps a plan; 0 means unlimited.
+type Limits struct {
+    MaxChildren        int
+    MaxActiveQuests    int
+    RecurringSchedules bool
+}
+
+// Unlimited is what premium grants.
+var Unlimited = Limits{RecurringSchedules: true}
+
+// Store is the part of the repository entitlements read.
+type Store interface {
+    GetBilling(ctx context.Context, parentID string) (*repopkg.Billing, error)
+    ListChildren(ctx context.Context, parentID string) ([]*model.Child, error)
+    ListQuests(ctx context.Context, parentID string) ([]*model.Quest, error)
+}
+
+// Checker resolves a household's plan and enforces its limits.
+type Checker struct {
+    Store Store
+    Free  Limits
+}
+
+// New returns a Checker with the free plan limits of cfg; a nil cfg leaves
+// the free plan unlimited.
+func New(store Store, cfg *config.Config) *Checker {
+    c := &Checker{Store: store, Free: Unlimited}
+    if cfg != nil {
+        c.Free = Limits{MaxChildren: cfg.FreeMaxChildren, MaxActiveQuests: cfg.FreeMaxActiveQuests, RecurringSchedules: cfg.FreeRecurringSchedules}
+    }
+    return c
+}
+
+// Household is a parent's plan and what it allows.
+type Household struct {
+    ParentID string
+    Plan     model.Plan
+    Limits   Limits
+}
+
+// For returns the plan of parentID's household.
+func (c *Checker) For(ctx context.Context, parentID string) (*Household, error) {
+    b, err := c.Store.GetBilling(ctx, parentID)
+    if err != nil { return nil, err }
+    if b.Active() { return &Household{ParentID: parentID, Plan: model.PlanPremium, Limits: Unlimited}, nil }
+    return &Household{ParentID: parentID, Plan: model.PlanFree, Limits: c.Free}, nil
+}
+
+// Usage is what a household has and which of it exceeds its plan.
+type Usage struct {
+    Children         int
+    Quests           int
+    ReadOnlyChildren []string
+    ReadOnlyQuests   []string
+}
+
+// Usage counts h's children and quests. Only limits that are set cost a query.
+func (c *Checker) Usage(ctx context.Context, h *Household) (*Usage, error) {
+    u := &Usage{}
+    children, err := c.Store.ListChildren(ctx, h.ParentID)
+    if err != nil { return nil, err }
+    ids := make([]dated, 0, len(children))
+    for _, ch := range children {
+        ids = append(ids, dated{ch.ID, ch.CreatedAt})
+    }
+    u.Children, u.ReadOnlyChildren = len(ids), beyond(ids, h.Limits.MaxChildren)
+    quests, err := c.Store.ListQuests(ctx, h.ParentID)
+    if err != nil { return nil, err }
+    ids = ids[:0]
+    for _, q := range quests {
+        ids = append(ids, dated{q.ID, q.CreatedAt})
+    }
+    u.Quests, u.ReadOnlyQuests = len(ids), beyond(ids, h.Limits.MaxActiveQuests)
+    return u, nil
+}
+
+// AddChild fails with PLAN_LIMIT when the household has no room for another child.
+func (c *Checker) AddChild(ctx context.Context, parentID string) error {
+    h, err := c.For(ctx, parentID)
+    if err != nil || h.Limits.MaxChildren == 0 { return err }
+    children, err := c.Store.ListChildren(ctx, parentID)
+    if err != nil { return err }
+    if len(children) >= h.Limits.MaxChildren {
+        return apperr.New(apperr.PlanLimit, "the free plan allows %d children; upgrade to add more", h.Limits.MaxChildren)
+    }
+    return nil
+}
+
+// AddQuest fails with PLAN_LIMIT when the household has no room for another quest.
+func (c *Checker) AddQuest(ctx context.Context, parentID string) error {
+    return c.AddQuests(ctx, parentID, 1)
+}
+
+// AddQuests fails with PLAN_LIMIT when the household has no room for n more quests.
+func (c *Checker) AddQuests(ctx context.Context, parentID string, n int) error {
+    h, err := c.For(ctx, parentID)
+    if err != nil || h.Limits.MaxActiveQuests == 0 { return err }
+    quests, err := c.Store.ListQuests(ctx, parentID)
+    if err != nil { return err }
+    if len(quests)+n > h.Limits.MaxActiveQuests {
+        return apperr.New(apperr.PlanLimit, "the free plan allows %d active quests; upgrade to add more", h.Limits.MaxActiveQuests)
+    }
+    return nil
+}
+
+// WriteChild fails with PLAN_LIMIT when childID is read-only on the household's plan.
+func (c *Checker) WriteChild(ctx context.Context, parentID, childID string) error {
+    h, err := c.For(ctx, parentID)
+    if err != nil || h.Limits.MaxChildren == 0 { return err }
+    u, err := c.Usage(ctx, h)
+    if err != nil { return err }
+    if contains(u.ReadOnlyChildren, childID) { return errReadOnly("child") }
+    return nil
+}
+
+// Assign fails with PLAN_LIMIT when the quest or the child is read-only.
+func (c *Checker) Assign(ctx context.Context, parentID, questID, childID string) error {
+    h, err := c.For(ctx, parentID)
+    if err != nil || h.Limits.MaxChildren == 0 && h.Limits.MaxActiveQuests == 0 { return err }
+    u, err := c.Usage(ctx, h)
+    if err != nil { return err }
+    if contains(u.ReadOnlyQuests, questID) { return errReadOnly("quest") }
+    if contains(u.ReadOnlyChildren, childID) { return errReadOnly("child") }
+    return nil
+}
+
+// Schedule fails with PLAN_LIMIT unless the plan includes recurring schedules.
+func (c *Checker) Schedule(ctx context.Context, parentID string) error {
+    h, err := c.For(ctx, parentID)
+    if err != nil { return err }
+    if !h.Limits.RecurringSchedules {
+        return apperr.New(apperr.PlanLimit, "recurring schedules need the premium plan")
+    }
+    return nil
+}
+
+func errReadOnly(what string) error {
+    return apperr.New(apperr.PlanLimit, "this %s is over the free plan limit and read-only; upgrade to change it", what)
+}
+
+type dated struct {
+    id      string
+    created *string
+}
+
+// beyond returns the IDs past the first max, oldest first; records without a
+// creation time count as oldest. max 0 means unlimited.
+func beyond(ids []dated, max int) []string {
+    out := []string{}
+    if max == 0 || len(ids) <= max { return out }
+    sort.Slice(ids, func(i, j int) bool {
+        a, b := deref(ids[i].created), deref(ids[j].created)
+        if a != b { return a < b }
+        return ids[i].id < ids[j].id
+    })
+    for _, d := range ids[max:] {
+        out = append(out, d.id)
+    }
+    return out
+}
+
+func deref(s *string) string {
+    if s == nil { return "" }
+    return *s
+}
+
+func contains(ids []string, id string) bool {
+    for _, v := range ids {
+        if v == id { return true }
+    }
+    return false
+}
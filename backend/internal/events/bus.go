@@ -0,0 +1,115 @@
+package events
+
+import (
+    "sync"
+    "time"
+
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+)
+
+// Type names a domain event.
+type Type string
+
+const (
+    AssignmentAssigned  Type = "assignment.assigned"
+    AssignmentCompleted Type = "assignment.completed"
+    ItemPurchased       Type = "item.purchased"
+    RewardRedeemed      Type = "reward.redeemed"
+    LevelUp             Type = "level.up"
+    PayoutRequested     Type = "payout.requested"
+    PayoutPaid          Type = "payout.paid"
+    PayoutDeclined      Type = "payout.declined"
+    SavingsDeposited    Type = "savings.deposited"
+    SavingsWithdrawn    Type = "savings.withdrawn"
+    SavingsBonus        Type = "savings.bonus"
+    GoldSent            Type = "gold.sent"
+    GoldReceived        Type = "gold.received"
+    TransferDeclined    Type = "transfer.declined"
+    PurchaseRequested   Type = "purchase.requested"
+    PurchaseDeclined    Type = "purchase.declined"
+)
+
+// Event is a domain change published after it has been persisted. ParentID and
+// ChildID identify the household and child it concerns; payload fields are set
+// according to Type.
+type Event struct {
+    ID              string                 `json:"id"`
+    Type            Type                   `json:"type"`
+    ParentID        string                 `json:"parentId"`
+    ChildID         string                 `json:"childId,omitempty"`
+    At              string                 `json:"at"`
+    Assignment      *model.Assignment      `json:"assignment,omitempty"`
+    Child           *model.Child           `json:"child,omitempty"`
+    Reward          *model.Reward          `json:"reward,omitempty"`
+    Item            *model.AvatarItem      `json:"item,omitempty"`
+    Level           int                    `json:"level,omitempty"`
+    Payout          *model.Payout          `json:"payout,omitempty"`
+    Goal            *model.SavingsGoal     `json:"goal,omitempty"`
+    Transfer        *model.GoldTransfer    `json:"transfer,omitempty"`
+    PurchaseRequest *model.PurchaseRequest `json:"purchaseRequest,omitempty"`
+}
+
+// New returns an event stamped with a fresh ID and the current time.
+func New(t Type, parentID, childID string) Event {
+    return Event{ID: uuid.NewString(), Type: t, ParentID: parentID, ChildID: childID, At: time.Now().UTC().Format(time.RFC3339)}
+}
+
+// subscriberBuffer bounds how far a slow subscriber may lag before events are dropped.
+const subscriberBuffer = 16
+
+type subscriber struct {
+    ch     chan Event
+    filter func(Event) bool
+}
+
+// Bus is an in-process publish/subscribe hub. Publish never blocks: a
+// subscriber whose buffer is full misses the event rather than stalling writers.
+type Bus struct {
+    mu   sync.RWMutex
+    next int
+    subs map[int]*subscriber
+}
+
+func NewBus() *Bus {
+    return &Bus{subs: map[int]*subscriber{}}
+}
+
+// Publish delivers e to every subscriber whose filter accepts it.
+func (b *Bus) Publish(e Event) {
+    if b == nil {
+        return
+    }
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    for _, s := range b.subs {
+        if s.filter != nil && !s.filter(e) {
+            continue
+        }
+        select {
+        case s.ch <- e:
+        default:
+        }
+    }
+}
+
+// Subscribe registers a subscriber. The returned cancel func unregisters it and
+// closes the channel; it is safe to call more than once.
+func (b *Bus) Subscribe(filter func(Event) bool) (<-chan Event, func()) {
+    b.mu.Lock()
+    id := b.next
+    b.next++
+    s := &subscriber{ch: make(chan Event, subscriberBuffer), filter: filter}
+    b.subs[id] = s
+    b.mu.Unlock()
+    var once sync.Once
+    return s.ch, func() {
+        once.Do(func() {
+            b.mu.Lock()
+            delete(b.subs, id)
+            b.mu.Unlock()
+            close(s.ch)
+        })
+    }
+}
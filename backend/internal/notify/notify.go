@@ -0,0 +1,162 @@
+// Package notify emails parents about household activity: a message when a
+// child completes a chore or buys something, and a weekly digest. Delivery
+// goes through a Sender so SMTP can be swapped for a log in development.
+package notify
+
+import (
+    "context"
+    "log"
+    "strings"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/events"
+    repopkg "chorequest/backend/internal/repo"
+    "chorequest/backend/internal/report"
+)
+
+// Message is one rendered email.
+type Message struct {
+    To      string
+    Subject string
+    Text    string
+    HTML    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+    Send(ctx context.Context, m Message) error
+}
+
+// LogSender writes messages to the server log instead of sending them.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, m Message) error {
+    log.Printf("notify: email to %s: %s\n%s", m.To, m.Subject, m.Text)
+    return nil
+}
+
+// Notifier turns events into emails according to each parent's preferences
+// and sends the weekly report as a digest.
+type Notifier struct {
+    Store  repopkg.Repo
+    Sender Sender
+
+    Timeout       time.Duration // per message
+    DigestHourUTC int           // digests go out on Mondays from this hour
+}
+
+func NewNotifier(store repopkg.Repo, sender Sender) *Notifier {
+    return &Notifier{Store: store, Sender: sender, Timeout: 10 * time.Second, DigestHourUTC: 8}
+}
+
+// Handle emails the household's parent about e when they asked for it. It is
+// an outbox handler; send failures are logged rather than returned, so a mail
+// outage does not hold back webhooks and live updates, and retries of the
+// event do not repeat emails that already went out.
+func (n *Notifier) Handle(ctx context.Context, e events.Event) error {
+    var tmpl string
+    switch e.Type {
+    case events.AssignmentCompleted:
+        tmpl = "chore_submitted"
+    case events.ItemPurchased:
+        tmpl = "item_purchased"
+    default:
+        return nil
+    }
+    p, err := n.Store.GetNotificationPreferences(ctx, e.ParentID)
+    if err != nil { return err }
+    if p.Email == nil || !wants(p, e.Type) { return nil }
+    m, err := render(tmpl, e)
+    if err != nil {
+        log.Printf("notify: render %s for %s: %v", tmpl, e.ID, err)
+        return nil
+    }
+    m.To = *p.Email
+    n.send(ctx, m)
+    return nil
+}
+
+func wants(p *model.NotificationPreferences, t events.Type) bool {
+    switch t {
+    case events.AssignmentCompleted:
+        return p.ChoreSubmitted
+    case events.ItemPurchased:
+        return p.ItemPurchased
+    }
+    return false
+}
+
+func (n *Notifier) send(ctx context.Context, m Message) bool {
+    ctx, cancel := context.WithTimeout(ctx, n.Timeout)
+    defer cancel()
+    if err := n.Sender.Send(ctx, m); err != nil {
+        log.Printf("notify: send %q to %s: %v", m.Subject, m.To, err)
+        return false
+    }
+    return true
+}
+
+// RunDigests checks hourly whether last week's digests are due and sends
+// them until ctx is cancelled.
+func (n *Notifier) RunDigests(ctx context.Context) {
+    tick := time.NewTicker(time.Hour)
+    defer tick.Stop()
+    for {
+        n.SendDigests(ctx, time.Now())
+        select {
+        case <-ctx.Done():
+            return
+        case <-tick.C:
+        }
+    }
+}
+
+// SendDigests emails the report for the week before now to every household
+// that has not received it yet. Nothing is sent before DigestHourUTC on Monday.
+// A household's claim is released when its digest cannot be built or sent, so
+// the next run tries again.
+func (n *Notifier) SendDigests(ctx context.Context, now time.Time) {
+    thisWeek := report.WeekStart(now)
+    if now.Sub(thisWeek) < time.Duration(n.DigestHourUTC)*time.Hour { return }
+    week := thisWeek.AddDate(0, 0, -7)
+    recipients, err := n.Store.DigestRecipients(ctx)
+    if err != nil {
+        log.Printf("notify: digest recipients: %v", err)
+        return
+    }
+    for _, p := range recipients {
+        if p.Email == nil { continue }
+        ok, err := n.Store.ClaimDigest(ctx, p.ParentID, week.Format(time.DateOnly))
+        if err != nil {
+            log.Printf("notify: claim digest %s: %v", p.ParentID, err)
+            continue
+        }
+        if !ok { continue }
+        if !n.sendDigest(ctx, p, week, now) {
+            if err := n.Store.ReleaseDigest(ctx, p.ParentID, week.Format(time.DateOnly)); err != nil {
+                log.Printf("notify: release digest %s: %v", p.ParentID, err)
+            }
+        }
+    }
+}
+
+func (n *Notifier) sendDigest(ctx context.Context, p *model.NotificationPreferences, week, now time.Time) bool {
+    rep, err := report.Weekly(ctx, n.Store, p.ParentID, week, now)
+    if err != nil {
+        log.Printf("notify: digest %s: %v", p.ParentID, err)
+        return false
+    }
+    m, err := render("weekly_digest", rep)
+    if err != nil {
+        log.Printf("notify: render digest %s: %v", p.ParentID, err)
+        return false
+    }
+    m.To = *p.Email
+    return n.send(ctx, m)
+}
+
+// headerSafe strips line breaks so user-provided names cannot inject headers.
+func headerSafe(s string) string {
+    return strings.Join(strings.Fields(s), " ")
+}
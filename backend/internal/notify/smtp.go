@@ -0,0 +1,89 @@
+package notify
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "crypto/tls"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "mime"
+    "mime/quotedprintable"
+    "net"
+    "net/mail"
+    "net/smtp"
+    "strings"
+    "time"
+)
+
+// SMTPSender delivers mail through an SMTP relay. STARTTLS is used whenever
+// the server offers it; credentials are only sent when Username is set.
+// A local sink such as MailHog works with just Addr and From.
+type SMTPSender struct {
+    Addr     string // host:port
+    From     string // "Name <addr>" or a bare address; MAIL FROM gets the address only
+    Username string
+    Password string
+}
+
+func (s *SMTPSender) Send(ctx context.Context, m Message) error {
+    host, _, err := net.SplitHostPort(s.Addr)
+    if err != nil { return err }
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "tcp", s.Addr)
+    if err != nil { return err }
+    if dl, ok := ctx.Deadline(); ok {
+        _ = conn.SetDeadline(dl)
+    }
+    c, err := smtp.NewClient(conn, host)
+    if err != nil {
+        conn.Close()
+        return err
+    }
+    defer c.Close()
+    if ok, _ := c.Extension("STARTTLS"); ok {
+        if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil { return err }
+    }
+    if s.Username != "" {
+        if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil { return err }
+    }
+    from, err := mail.ParseAddress(s.From)
+    if err != nil { return fmt.Errorf("smtp from: %w", err) }
+    body, err := s.compose(m, from)
+    if err != nil { return err }
+    if err := c.Mail(from.Address); err != nil { return err }
+    if err := c.Rcpt(m.To); err != nil { return err }
+    w, err := c.Data()
+    if err != nil { return err }
+    if _, err := w.Write(body); err != nil { return err }
+    if err := w.Close(); err != nil { return err }
+    return c.Quit()
+}
+
+// compose renders m as a multipart/alternative message with text and HTML parts.
+func (s *SMTPSender) compose(m Message, from *mail.Address) ([]byte, error) {
+    if strings.ContainsAny(m.To, "\r\n") {
+        return nil, errors.New("invalid address")
+    }
+    b := make([]byte, 12)
+    if _, err := rand.Read(b); err != nil { return nil, err }
+    boundary := "cq-" + hex.EncodeToString(b)
+    var buf bytes.Buffer
+    fmt.Fprintf(&buf, "From: %s\r\n", from)
+    fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+    fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+    fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+    buf.WriteString("MIME-Version: 1.0\r\n")
+    fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+    for _, part := range []struct{ typ, body string }{{"text/plain", m.Text}, {"text/html", m.HTML}} {
+        fmt.Fprintf(&buf, "--%s\r\n", boundary)
+        fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", part.typ)
+        qp := quotedprintable.NewWriter(&buf)
+        if _, err := qp.Write([]byte(part.body)); err != nil { return nil, err }
+        if err := qp.Close(); err != nil { return nil, err }
+        buf.WriteString("\r\n")
+    }
+    fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+    return buf.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+    "bytes"
+    "embed"
+    "fmt"
+    htmltemplate "html/template"
+    "io/fs"
+    "strings"
+    texttemplate "text/template"
+    "time"
+
+    "chorequest/backend/graph/model"
+)
+
+// Each email has a <name>.txt.tmpl defining "subject" and the plain body, and
+// a <name>.html.tmpl defining "content" for layout.html.tmpl.
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+type emailTemplate struct {
+    text *texttemplate.Template
+    html *htmltemplate.Template
+}
+
+var templates = loadTemplates()
+
+var funcs = map[string]any{
+    "level": model.LevelForXP,
+    // day formats a YYYY-MM-DD date or an RFC 3339 timestamp as e.g. "Oct 12";
+    // anything else is shown as is rather than failing the email.
+    "day": func(s string) string {
+        t, err := time.Parse(time.DateOnly, s)
+        if err != nil { t, err = time.Parse(time.RFC3339, s) }
+        if err != nil { return s }
+        return t.Format("Jan 2")
+    },
+}
+
+func loadTemplates() map[string]emailTemplate {
+    files, err := fs.Glob(templateFS, "templates/*.txt.tmpl")
+    if err != nil { panic(err) }
+    out := make(map[string]emailTemplate, len(files))
+    for _, f := range files {
+        name := strings.TrimSuffix(strings.TrimPrefix(f, "templates/"), ".txt.tmpl")
+        out[name] = emailTemplate{
+            text: texttemplate.Must(texttemplate.New(name).Funcs(funcs).ParseFS(templateFS, f)),
+            html: htmltemplate.Must(htmltemplate.New(name).Funcs(funcs).ParseFS(templateFS, "templates/layout.html.tmpl", "templates/"+name+".html.tmpl")),
+        }
+    }
+    return out
+}
+
+// render builds the message for template name from data; the caller sets To.
+func render(name string, data any) (Message, error) {
+    t, ok := templates[name]
+    if !ok { return Message{}, fmt.Errorf("unknown email template %q", name) }
+    var subject, text, html bytes.Buffer
+    if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil { return Message{}, err }
+    if err := t.text.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil { return Message{}, err }
+    if err := t.html.ExecuteTemplate(&html, "layout.html.tmpl", data); err != nil { return Message{}, err }
+    return Message{Subject: headerSafe(subject.String()), Text: strings.TrimSpace(text.String()) + "\n", HTML: html.String()}, nil
+}
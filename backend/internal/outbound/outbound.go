@@ -0,0 +1,51 @@
+// Package outbound builds HTTP clients for requests to user-supplied URLs,
+// such as webhook receivers and push endpoints.
+package outbound
+
+import (
+    "fmt"
+    "net"
+    "net/http"
+    "net/netip"
+    "syscall"
+    "time"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), internal to
+// many cloud networks but not covered by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Public reports whether a may be dialled on behalf of a user: loopback,
+// private, link-local (including cloud metadata at 169.254.169.254),
+// multicast and unspecified addresses are not.
+func Public(a netip.Addr) bool {
+    a = a.Unmap()
+    return a.IsValid() && !a.IsLoopback() && !a.IsPrivate() && !a.IsLinkLocalUnicast() && !a.IsLinkLocalMulticast() &&
+        !a.IsInterfaceLocalMulticast() && !a.IsMulticast() && !a.IsUnspecified() && !sharedAddressSpace.Contains(a) &&
+        !(a.Is4() && a.As4()[0] == 0)
+}
+
+// NewClient returns a client that never follows redirects. With restrict set
+// it also refuses to connect to non-public addresses; the check runs on the
+// resolved address at connection time, so hostnames that resolve or rebind to
+// internal addresses are caught too. Restricted clients ignore proxy settings,
+// since the check would otherwise see the proxy's address.
+func NewClient(timeout time.Duration, restrict bool) *http.Client {
+    t := http.DefaultTransport.(*http.Transport).Clone()
+    if restrict {
+        d := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: publicOnly}
+        t.Proxy, t.DialContext = nil, d.DialContext
+    }
+    return &http.Client{
+        Transport:     t,
+        Timeout:       timeout,
+        CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+    }
+}
+
+func publicOnly(network, address string, _ syscall.RawConn) error {
+    ap, err := netip.ParseAddrPort(address)
+    if err != nil { return fmt.Errorf("outbound: %w", err) }
+    if !Public(ap.Addr()) { return fmt.Errorf("outbound: refusing to connect to non-public address %s", ap.Addr()) }
+    return nil
+}
@@ -0,0 +1,151 @@
+// Package outbox publishes events that the repository committed together with
+// the state changes they describe. Subscribers therefore never see events for
+// rolled-back writes, and committed events survive crashes until published.
+package outbox
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "chorequest/backend/internal/events"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// Handler consumes an event reliably; an error leaves the event pending so it
+// is retried with backoff until the dispatcher gives up. Handlers must
+// tolerate repeats.
+type Handler func(context.Context, events.Event) error
+
+// Dispatcher polls the outbox, runs Handlers for each pending event and then
+// publishes it to Bus for best-effort subscribers. Delivery is at least once:
+// an event whose dispatcher crashed before it was marked delivered is
+// dispatched again once its lease expires, and one whose handler failed after
+// a backoff. Events still failing after MaxAttempts are dead-lettered.
+type Dispatcher struct {
+    Store    repopkg.Repo
+    Bus      *events.Bus
+    Handlers []Handler
+
+    MaxAttempts  int           // attempts, crashed ones included, before an event is dead-lettered
+    BaseBackoff  time.Duration // delay after the first failure; doubles each retry
+    MaxBackoff   time.Duration
+    PollInterval time.Duration
+    Lease        time.Duration
+    BatchSize    int
+
+    kick chan struct{}
+}
+
+// NewDispatcher returns a Dispatcher with production defaults: 10 attempts
+// spread over about three and a half hours.
+func NewDispatcher(store repopkg.Repo, bus *events.Bus) *Dispatcher {
+    return &Dispatcher{
+        Store:        store,
+        Bus:          bus,
+        MaxAttempts:  10,
+        BaseBackoff:  30 * time.Second,
+        MaxBackoff:   time.Hour,
+        PollInterval: 2 * time.Second,
+        Lease:        30 * time.Second,
+        BatchSize:    50,
+        kick:         make(chan struct{}, 1),
+    }
+}
+
+// Wake asks the dispatcher to poll now instead of at its next tick; the
+// repository calls it after committing events.
+func (d *Dispatcher) Wake() {
+    select {
+    case d.kick <- struct{}{}:
+    default:
+    }
+}
+
+// Run publishes pending events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+    tick := time.NewTicker(d.PollInterval)
+    defer tick.Stop()
+    for {
+        d.drain(ctx)
+        select {
+        case <-ctx.Done():
+            return
+        case <-d.kick:
+        case <-tick.C:
+        }
+    }
+}
+
+// drain publishes batches until no due entries remain.
+func (d *Dispatcher) drain(ctx context.Context) {
+    for ctx.Err() == nil {
+        pending, err := d.Store.PendingOutbox(ctx, time.Now(), d.BatchSize)
+        if err != nil {
+            log.Printf("outbox: poll: %v", err)
+            return
+        }
+        for _, o := range pending {
+            ok, err := d.Store.LeaseOutbox(ctx, o, time.Now().Add(d.Lease))
+            if err != nil {
+                log.Printf("outbox: lease %s: %v", o.Event.ID, err)
+                continue
+            }
+            if !ok {
+                continue
+            }
+            // Leases taken by dispatchers that crashed while handling count too,
+            // so an event that takes the process down is eventually set aside.
+            if o.Attempts > d.MaxAttempts {
+                d.fail(ctx, o, fmt.Errorf("gave up after %d attempts", d.MaxAttempts))
+                continue
+            }
+            if err := d.handle(ctx, o.Event); err != nil {
+                d.fail(ctx, o, err)
+                continue
+            }
+            d.Bus.Publish(o.Event)
+            if err := d.Store.MarkOutboxDelivered(ctx, o.Event.ID); err != nil {
+                log.Printf("outbox: mark %s delivered: %v", o.Event.ID, err)
+            }
+        }
+        if len(pending) < d.BatchSize {
+            return
+        }
+    }
+}
+
+// fail schedules a leased entry for another attempt, or dead-letters it once
+// its attempts are exhausted.
+func (d *Dispatcher) fail(ctx context.Context, o *repopkg.OutboxEntry, cause error) {
+    var next *time.Time
+    if o.Attempts < d.MaxAttempts {
+        t := time.Now().Add(d.backoff(o.Attempts))
+        next = &t
+        log.Printf("outbox: handle %s %s (attempt %d): %v", o.Event.Type, o.Event.ID, o.Attempts, cause)
+    } else {
+        log.Printf("outbox: dead-lettering %s %s: %v", o.Event.Type, o.Event.ID, cause)
+    }
+    if err := d.Store.FailOutbox(ctx, o, cause.Error(), next); err != nil {
+        log.Printf("outbox: record failure of %s: %v", o.Event.ID, err)
+    }
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+    b := d.BaseBackoff
+    for i := 1; i < attempts && b < d.MaxBackoff; i++ {
+        b *= 2
+    }
+    return min(b, d.MaxBackoff)
+}
+
+func (d *Dispatcher) handle(ctx context.Context, e events.Event) error {
+    for _, h := range d.Handlers {
+        if err := h(ctx, e); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,94 @@
+package push
+
+import (
+    "bytes"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/ecdh"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+)
+
+// recordSize is the aes128gcm record size advertised in the header; payloads
+// are sent as a single record.
+const recordSize = 4096
+
+// MaxPayload is the largest plaintext Encrypt accepts, so that the whole body
+// (86-byte header, delimiter and 16-byte tag) fits the 4096 bytes push
+// services guarantee.
+const MaxPayload = 4096 - 86 - 1 - 16
+
+// Encrypt seals plaintext for a subscription as an aes128gcm body (RFC 8291,
+// RFC 8188) using a fresh ephemeral key and salt.
+func Encrypt(uaPublic *ecdh.PublicKey, authSecret, plaintext []byte) ([]byte, error) {
+    if len(plaintext) > MaxPayload { return nil, errors.New("push payload too large") }
+    as, err := ecdh.P256().GenerateKey(rand.Reader)
+    if err != nil { return nil, err }
+    salt := make([]byte, 16)
+    if _, err := rand.Read(salt); err != nil { return nil, err }
+    shared, err := as.ECDH(uaPublic)
+    if err != nil { return nil, err }
+    asPublic := as.PublicKey().Bytes()
+    gcm, nonce, err := contentKeys(shared, authSecret, salt, uaPublic.Bytes(), asPublic)
+    if err != nil { return nil, err }
+
+    var body bytes.Buffer
+    body.Write(salt)
+    _ = binary.Write(&body, binary.BigEndian, uint32(recordSize))
+    body.WriteByte(byte(len(asPublic)))
+    body.Write(asPublic)
+    record := append(append([]byte{}, plaintext...), 0x02) // last-record delimiter, no padding
+    return gcm.Seal(body.Bytes(), nonce, record, nil), nil
+}
+
+// Decrypt opens a body produced by Encrypt with the subscription's private
+// key, as a user agent would. It is used by the local push-service stand-in.
+func Decrypt(ua *ecdh.PrivateKey, authSecret, body []byte) ([]byte, error) {
+    if len(body) < 21 { return nil, errors.New("push body too short") }
+    salt, idLen := body[:16], int(body[20])
+    if len(body) < 21+idLen { return nil, errors.New("push body too short") }
+    asPublic := body[21 : 21+idLen]
+    pub, err := ecdh.P256().NewPublicKey(asPublic)
+    if err != nil { return nil, err }
+    shared, err := ua.ECDH(pub)
+    if err != nil { return nil, err }
+    gcm, nonce, err := contentKeys(shared, authSecret, salt, ua.PublicKey().Bytes(), asPublic)
+    if err != nil { return nil, err }
+    record, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+    if err != nil { return nil, err }
+    i := bytes.LastIndexByte(record, 0x02)
+    if i < 0 { return nil, errors.New("push record has no delimiter") }
+    return record[:i], nil
+}
+
+// contentKeys derives the AES-GCM key and nonce for one message.
+func contentKeys(shared, authSecret, salt, uaPublic, asPublic []byte) (cipher.AEAD, []byte, error) {
+    keyInfo := append(append([]byte("WebPush: info\x00"), uaPublic...), asPublic...)
+    ikm := hkdf(authSecret, shared, keyInfo, 32)
+    prk := extract(salt, ikm)
+    cek := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+    nonce := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+    block, err := aes.NewCipher(cek)
+    if err != nil { return nil, nil, err }
+    gcm, err := cipher.NewGCM(block)
+    return gcm, nonce, err
+}
+
+// HKDF-SHA-256 (RFC 5869), limited to a single output block.
+func hkdf(salt, ikm, info []byte, n int) []byte { return expand(extract(salt, ikm), info, n) }
+
+func extract(salt, ikm []byte) []byte {
+    h := hmac.New(sha256.New, salt)
+    h.Write(ikm)
+    return h.Sum(nil)
+}
+
+func expand(prk, info []byte, n int) []byte {
+    h := hmac.New(sha256.New, prk)
+    h.Write(info)
+    h.Write([]byte{1})
+    return h.Sum(nil)[:n]
+}
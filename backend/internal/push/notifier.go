@@ -0,0 +1,127 @@
+package push
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/events"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// Message is the JSON payload the client's service worker receives.
+type Message struct {
+    Type  string `json:"type"` // quest.assigned, quest.approved or reward.ready
+    Title string `json:"title"`
+    Body  string `json:"body"`
+    Tag   string `json:"tag,omitempty"` // lets the client collapse related notifications
+}
+
+// Notifier pushes household events to the devices of the child they concern.
+type Notifier struct {
+    Store  repopkg.Repo
+    Client *Client
+    Now    func() time.Time
+}
+
+func NewNotifier(store repopkg.Repo, client *Client) *Notifier {
+    return &Notifier{Store: store, Client: client, Now: time.Now}
+}
+
+// Handle is an outbox handler. Like email, pushes are best effort: send
+// failures are logged, and expired subscriptions are deleted. Nothing is sent
+// during the child's quiet hours.
+func (n *Notifier) Handle(ctx context.Context, e events.Event) error {
+    msgs, err := n.messages(ctx, e)
+    if err != nil || len(msgs) == 0 { return err }
+    q, err := n.Store.GetQuietHours(ctx, e.ChildID)
+    if err != nil { return err }
+    if q != nil && InQuietHours(q, n.Now()) { return nil }
+    targets, err := n.Store.ListPushSubscriptions(ctx, e.ChildID)
+    if err != nil { return err }
+    for _, m := range msgs {
+        payload, err := json.Marshal(m)
+        if err != nil { return err }
+        for _, t := range targets {
+            err := n.Client.Send(ctx, Subscription{Endpoint: t.Subscription.Endpoint, P256dh: t.P256dh, Auth: t.Auth}, payload, "normal")
+            switch {
+            case errors.Is(err, ErrGone):
+                if _, err := n.Store.DeletePushSubscription(ctx, e.ChildID, t.Subscription.ID); err != nil {
+                    log.Printf("push: delete expired %s: %v", t.Subscription.ID, err)
+                }
+            case err != nil:
+                log.Printf("push: send %s to %s: %v", m.Type, t.Subscription.ID, err)
+            }
+        }
+    }
+    return nil
+}
+
+// messages returns what to tell the child about e, if anything.
+func (n *Notifier) messages(ctx context.Context, e events.Event) ([]Message, error) {
+    a := e.Assignment
+    switch {
+    case e.Type == events.AssignmentAssigned && a != nil && a.Quest != nil:
+        var m *model.DifficultyMultipliers
+        if a.Quest.Difficulty != nil {
+            var err error
+            if m, err = n.Store.GetDifficultyMultipliers(ctx, e.ParentID); err != nil { return nil, err }
+        }
+        xp, gold := m.Pay(a.Quest)
+        return []Message{{
+            Type: "quest.assigned", Title: "New quest: " + a.Quest.Title,
+            Body: fmt.Sprintf("Worth %d XP and %d gold.", xp, gold), Tag: "assignment-" + a.ID,
+        }}, nil
+    case e.Type == events.AssignmentCompleted && a != nil && a.Quest != nil:
+        msgs := []Message{{
+            Type: "quest.approved", Title: "Quest approved: " + a.Quest.Title,
+            Body: fmt.Sprintf("You earned %d XP and %d gold.", a.EarnedXp(), a.EarnedGold()), Tag: "assignment-" + a.ID,
+        }}
+        if e.Child == nil { return msgs, nil }
+        ready, err := n.rewardsReached(ctx, e.ParentID, e.Child.Xp-a.EarnedXp(), e.Child.Xp)
+        if err != nil { return nil, err }
+        for _, r := range ready {
+            msgs = append(msgs, Message{Type: "reward.ready", Title: "Reward ready: " + r.Name, Body: "You have enough XP to redeem it.", Tag: "reward-" + r.ID})
+        }
+        return msgs, nil
+    }
+    return nil, nil
+}
+
+// rewardsReached lists rewards whose threshold lies in (before, after].
+func (n *Notifier) rewardsReached(ctx context.Context, parentID string, before, after int) ([]*model.Reward, error) {
+    rewards, err := n.Store.ListRewards(ctx, parentID)
+    if err != nil { return nil, err }
+    var out []*model.Reward
+    for _, r := range rewards {
+        if r.XpThreshold > before && r.XpThreshold <= after {
+            out = append(out, r)
+        }
+    }
+    return out, nil
+}
+
+// InQuietHours reports whether t falls in q's daily window, which may wrap
+// midnight. An invalid window or time zone never silences.
+func InQuietHours(q *model.QuietHours, t time.Time) bool {
+    loc, err := time.LoadLocation(q.TimeZone)
+    if err != nil { return false }
+    start, err1 := ParseClock(q.Start)
+    end, err2 := ParseClock(q.End)
+    if err1 != nil || err2 != nil { return false }
+    t = t.In(loc)
+    m := t.Hour()*60 + t.Minute()
+    if start <= end { return m >= start && m < end }
+    return m >= start || m < end
+}
+
+// ParseClock parses "HH:MM" into minutes after midnight.
+func ParseClock(s string) (int, error) {
+    t, err := time.Parse("15:04", s)
+    if err != nil { return 0, errors.New("must be HH:MM") }
+    return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,83 @@
+// Package push sends Web Push notifications (RFC 8030) with VAPID
+// authentication and aes128gcm payload encryption, and decides which of a
+// child's devices to notify about household events.
+package push
+
+import (
+    "bytes"
+    "context"
+    "crypto/ecdh"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "time"
+
+    "chorequest/backend/internal/outbound"
+)
+
+// ErrGone reports that the push service no longer knows the subscription;
+// it should be deleted.
+var ErrGone = errors.New("push subscription expired")
+
+// Subscription is the browser's PushSubscription: endpoint plus the p256dh
+// and auth keys, base64url encoded.
+type Subscription struct {
+    Endpoint string
+    P256dh   string
+    Auth     string
+}
+
+// Keys decodes and checks the subscription's keys.
+func (s Subscription) Keys() (*ecdh.PublicKey, []byte, error) {
+    raw, err := decodeKey(s.P256dh)
+    if err != nil { return nil, nil, fmt.Errorf("p256dh: %w", err) }
+    pub, err := ecdh.P256().NewPublicKey(raw)
+    if err != nil { return nil, nil, fmt.Errorf("p256dh: %w", err) }
+    auth, err := decodeKey(s.Auth)
+    if err != nil { return nil, nil, fmt.Errorf("auth: %w", err) }
+    if len(auth) != 16 { return nil, nil, errors.New("auth: must be 16 bytes") }
+    return pub, auth, nil
+}
+
+// Client posts encrypted messages to push services.
+type Client struct {
+    VAPID *VAPID
+    HTTP  *http.Client
+    TTL   time.Duration // how long the push service may hold an undelivered message
+}
+
+// NewClient only dials public addresses and does not follow redirects.
+func NewClient(v *VAPID) *Client {
+    return &Client{VAPID: v, HTTP: outbound.NewClient(10*time.Second, true), TTL: 24 * time.Hour}
+}
+
+// Send encrypts payload for s and posts it with the given Urgency ("normal",
+// "high", ...). It returns ErrGone when the subscription has expired.
+func (c *Client) Send(ctx context.Context, s Subscription, payload []byte, urgency string) error {
+    pub, auth, err := s.Keys()
+    if err != nil { return err }
+    body, err := Encrypt(pub, auth, payload)
+    if err != nil { return err }
+    authz, err := c.VAPID.authorization(s.Endpoint, time.Now())
+    if err != nil { return err }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+    if err != nil { return err }
+    req.Header.Set("Authorization", authz)
+    req.Header.Set("Content-Type", "application/octet-stream")
+    req.Header.Set("Content-Encoding", "aes128gcm")
+    req.Header.Set("TTL", strconv.Itoa(int(c.TTL.Seconds())))
+    if urgency != "" { req.Header.Set("Urgency", urgency) }
+    resp, err := c.HTTP.Do(req)
+    if err != nil { return err }
+    defer resp.Body.Close()
+    _, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+    switch {
+    case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+        return ErrGone
+    case resp.StatusCode < 200 || resp.StatusCode > 299:
+        return fmt.Errorf("push service responded %s", resp.Status)
+    }
+    return nil
+}
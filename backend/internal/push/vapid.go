@@ -0,0 +1,94 @@
+package push
+
+import (
+    "crypto/ecdh"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "math/big"
+    "net/url"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// VAPID identifies this application server to push services (RFC 8292).
+type VAPID struct {
+    key       *ecdsa.PrivateKey
+    publicKey string
+    Subject   string // mailto: or https: contact for the push service operator
+}
+
+// GenerateVAPIDKeys returns a new P-256 key pair encoded as unpadded base64url:
+// the 65-byte uncompressed public key and the 32-byte private scalar.
+func GenerateVAPIDKeys() (public, private string, err error) {
+    k, err := ecdh.P256().GenerateKey(rand.Reader)
+    if err != nil { return "", "", err }
+    return b64.EncodeToString(k.PublicKey().Bytes()), b64.EncodeToString(k.Bytes()), nil
+}
+
+// ParseVAPID loads a key pair produced by GenerateVAPIDKeys.
+func ParseVAPID(public, private, subject string) (*VAPID, error) {
+    raw, err := decodeKey(private)
+    if err != nil { return nil, fmt.Errorf("vapid private key: %w", err) }
+    k, err := ecdh.P256().NewPrivateKey(raw)
+    if err != nil { return nil, fmt.Errorf("vapid private key: %w", err) }
+    pub := k.PublicKey().Bytes()
+    if public != "" && public != b64.EncodeToString(pub) {
+        return nil, errors.New("vapid public key does not match the private key")
+    }
+    ek := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(raw)}
+    ek.PublicKey = ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(pub[1:33]), Y: new(big.Int).SetBytes(pub[33:])}
+    return &VAPID{key: ek, publicKey: b64.EncodeToString(pub), Subject: subject}, nil
+}
+
+// PublicKey is the applicationServerKey clients pass to PushManager.subscribe.
+func (v *VAPID) PublicKey() string { return v.publicKey }
+
+// authorization returns the Authorization header for a request to endpoint.
+func (v *VAPID) authorization(endpoint string, now time.Time) (string, error) {
+    u, err := url.Parse(endpoint)
+    if err != nil { return "", err }
+    claims := jwt.MapClaims{"aud": u.Scheme + "://" + u.Host, "exp": now.Add(12 * time.Hour).Unix()}
+    if v.Subject != "" { claims["sub"] = v.Subject }
+    t, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(v.key)
+    if err != nil { return "", err }
+    return "vapid t=" + t + ", k=" + v.publicKey, nil
+}
+
+// VerifyAuthorization checks a VAPID Authorization header as a push service
+// would: the JWT must be signed by key k and addressed to audience.
+func VerifyAuthorization(header, audience string) error {
+    var token, key string
+    if _, err := fmt.Sscanf(header, "vapid t=%s k=%s", &token, &key); err != nil {
+        return errors.New("malformed vapid authorization")
+    }
+    token = trimComma(token)
+    raw, err := decodeKey(key)
+    if err != nil { return err }
+    pub, err := ecdh.P256().NewPublicKey(raw)
+    if err != nil { return err }
+    b := pub.Bytes()
+    ek := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(b[1:33]), Y: new(big.Int).SetBytes(b[33:])}
+    _, err = jwt.Parse(token, func(*jwt.Token) (any, error) { return ek, nil },
+        jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(audience), jwt.WithExpirationRequired())
+    return err
+}
+
+func trimComma(s string) string {
+    if n := len(s); n > 0 && s[n-1] == ',' { return s[:n-1] }
+    return s
+}
+
+var b64 = base64.RawURLEncoding
+
+// decodeKey accepts base64url with or without padding, as browsers differ.
+func decodeKey(s string) ([]byte, error) {
+    for len(s) > 0 && s[len(s)-1] == '=' {
+        s = s[:len(s)-1]
+    }
+    return b64.DecodeString(s)
+}
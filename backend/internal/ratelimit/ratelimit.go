@@ -0,0 +1,157 @@
+// Package ratelimit provides token-bucket rate limiting with pluggable storage.
+package ratelimit
+
+import (
+    "context"
+    "math"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// Limit allows Requests per Per on average, with bursts of up to Requests.
+type Limit struct {
+    Requests int
+    Per      time.Duration
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool { return l.Requests > 0 && l.Per > 0 }
+
+func (l Limit) rate() float64 { return float64(l.Requests) / l.Per.Seconds() }
+
+// Store tracks buckets. Memory is the in-process implementation; a shared
+// store (e.g. Redis or DynamoDB) lets several server instances share budgets.
+type Store interface {
+    // Allow takes one token from key's bucket. When none is available it
+    // returns false and how long until the next token.
+    Allow(ctx context.Context, key string, l Limit) (ok bool, retryAfter time.Duration, err error)
+    // Wait reports how long until key's bucket has a token, without taking
+    // one; zero means Allow would succeed now.
+    Wait(ctx context.Context, key string, l Limit) (time.Duration, error)
+}
+
+type bucket struct {
+    tokens float64
+    last   time.Time
+    per    time.Duration
+}
+
+// Memory is a Store backed by a map guarded by a mutex. Idle buckets are
+// swept lazily so the map does not grow without bound.
+type Memory struct {
+    mu        sync.Mutex
+    buckets   map[string]*bucket
+    lastSweep time.Time
+    now       func() time.Time
+}
+
+func NewMemory() *Memory {
+    return &Memory{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+const sweepEvery = time.Minute
+
+func (m *Memory) Allow(_ context.Context, key string, l Limit) (bool, time.Duration, error) {
+    if !l.Enabled() {
+        return true, 0, nil
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    b := m.refill(key, l)
+    if b.tokens >= 1 {
+        b.tokens--
+        return true, 0, nil
+    }
+    return false, b.wait(l), nil
+}
+
+func (m *Memory) Wait(_ context.Context, key string, l Limit) (time.Duration, error) {
+    if !l.Enabled() {
+        return 0, nil
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.refill(key, l).wait(l), nil
+}
+
+// refill returns key's bucket topped up to now; m.mu must be held.
+func (m *Memory) refill(key string, l Limit) *bucket {
+    now := m.now()
+    if now.Sub(m.lastSweep) > sweepEvery {
+        m.sweep(now)
+        m.lastSweep = now
+    }
+    b, ok := m.buckets[key]
+    if !ok {
+        b = &bucket{tokens: float64(l.Requests), last: now, per: l.Per}
+        m.buckets[key] = b
+    }
+    b.tokens = math.Min(float64(l.Requests), b.tokens+now.Sub(b.last).Seconds()*l.rate())
+    b.last = now
+    return b
+}
+
+func (b *bucket) wait(l Limit) time.Duration {
+    if b.tokens >= 1 {
+        return 0
+    }
+    return time.Duration((1 - b.tokens) / l.rate() * float64(time.Second))
+}
+
+// sweep drops buckets idle long enough to have refilled completely; a fresh
+// bucket starts full, so forgetting them changes nothing.
+func (m *Memory) sweep(now time.Time) {
+    for k, b := range m.buckets {
+        if now.Sub(b.last) > b.per {
+            delete(m.buckets, k)
+        }
+    }
+}
+
+type ctxKey string
+
+const headerKey ctxKey = "responseHeader"
+
+// WithResponseHeader lets code deeper in the stack (e.g. GraphQL extensions)
+// set Retry-After on the HTTP response.
+func WithResponseHeader(ctx context.Context, h http.Header) context.Context {
+    return context.WithValue(ctx, headerKey, h)
+}
+
+// SetRetryAfter sets the Retry-After header, rounded up to whole seconds, if
+// ctx carries a response header.
+func SetRetryAfter(ctx context.Context, d time.Duration) {
+    if h, ok := ctx.Value(headerKey).(http.Header); ok {
+        h.Set("Retry-After", RetryAfterSeconds(d))
+    }
+}
+
+// RetryAfterSeconds formats d for the Retry-After header (minimum 1 second).
+func RetryAfterSeconds(d time.Duration) string {
+    s := int(math.Ceil(d.Seconds()))
+    if s < 1 {
+        s = 1
+    }
+    return strconv.Itoa(s)
+}
+
+// Middleware applies l to every request, keyed by key(r) under the given
+// budget name. Denied requests get 429 with Retry-After. Store errors fail open.
+func Middleware(store Store, budget string, l Limit, key func(*http.Request) string) func(http.Handler) http.Handler {
+    return func(next http.Handler) http.Handler {
+        if store == nil || !l.Enabled() {
+            return next
+        }
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            ok, wait, err := store.Allow(r.Context(), budget+":"+key(r), l)
+            if err == nil && !ok {
+                w.Header().Set("Retry-After", RetryAfterSeconds(wait))
+                http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+                return
+            }
+            next.ServeHTTP(w, r.WithContext(WithResponseHeader(r.Context(), w.Header())))
+        })
+    }
+}
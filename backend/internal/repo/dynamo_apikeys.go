@@ -0,0 +1,103 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// API keys
+func (r *DynamoRepo) CreateAPIKey(ctx context.Context, parentID, name, prefix, hash string, scopes []model.APIKeyScope) (*model.APIKey, error) {
+    kid := uuid.NewString()
+    it := item{
+        PK: pkParent(parentID), SK: skAPIKey(kid), Type: "ApiKey",
+        ParentID: parentID, Name: name, Prefix: prefix, KeyHash: hash, Created: NowRFC3339(),
+    }
+    for _, s := range scopes {
+        it.Scopes = append(it.Scopes, string(s))
+    }
+    it.GSI2PK, it.GSI2SK = gsi2Key("APIKEY", hash)
+    av, _ := attributevalue.MarshalMap(it)
+    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
+        return nil, err
+    }
+    return toAPIKey(it), nil
+}
+
+func (r *DynamoRepo) ListAPIKeys(ctx context.Context, parentID string) ([]*model.APIKey, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+            ":sk": &types.AttributeValueMemberS{Value: "APIKEY#"},
+        },
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.APIKey, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toAPIKey(it))
+    }
+    return res, nil
+}
+
+func (r *DynamoRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+    it, err := r.getMeta(ctx, "APIKEY", hash)
+    if err != nil { return nil, err }
+    if it == nil { return nil, apperr.New(apperr.NotFound, "api key not found") }
+    return toAPIKey(*it), nil
+}
+
+func (r *DynamoRepo) TouchAPIKey(ctx context.Context, parentID, keyID string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: pkParent(parentID)}, "SK": &types.AttributeValueMemberS{Value: skAPIKey(keyID)}},
+        UpdateExpression:    aws.String("SET LastUsedAt = :t"),
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":t": &types.AttributeValueMemberS{Value: NowRFC3339()},
+        },
+    })
+    return err
+}
+
+func (r *DynamoRepo) RevokeAPIKey(ctx context.Context, parentID, keyID string) (*model.APIKey, error) {
+    out, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: pkParent(parentID)}, "SK": &types.AttributeValueMemberS{Value: skAPIKey(keyID)}},
+        UpdateExpression:    aws.String("SET RevokedAt = if_not_exists(RevokedAt, :t)"),
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":t": &types.AttributeValueMemberS{Value: NowRFC3339()},
+        },
+        ReturnValues: types.ReturnValueAllNew,
+    })
+    if err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "api key not found") }
+        return nil, err
+    }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &it); err != nil { return nil, err }
+    return toAPIKey(it), nil
+}
+
+func toAPIKey(it item) *model.APIKey {
+    k := &model.APIKey{
+        ID: strings.TrimPrefix(it.SK, "APIKEY#"), ParentID: it.ParentID, Name: it.Name, Prefix: it.Prefix,
+        Scopes: make([]model.APIKeyScope, 0, len(it.Scopes)), CreatedAt: it.Created, LastUsedAt: it.UsedAt, RevokedAt: it.Revoked,
+    }
+    for _, s := range it.Scopes {
+        k.Scopes = append(k.Scopes, model.APIKeyScope(s))
+    }
+    return k
+}
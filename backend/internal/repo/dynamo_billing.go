@@ -0,0 +1,131 @@
+package repo
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// Billing state lives under the parent and is found from a Stripe customer
+// through GSI2. Subscription updates carry the Stripe event time and only
+// apply if no later event has been applied, as Stripe may deliver out of order.
+const skBilling = "BILLING"
+
+func billingKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skBilling},
+    }
+}
+
+// GetBilling returns the parent's billing state; empty before any checkout.
+func (r *DynamoRepo) GetBilling(ctx context.Context, parentID string) (*Billing, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: billingKey(parentID)})
+    if err != nil { return nil, err }
+    if out.Item == nil { return &Billing{}, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return &Billing{
+        CustomerID: it.CustomerID, SubscriptionID: it.SubscriptionID, Status: it.Status,
+        CurrentPeriodEnd: it.PeriodEnd, CancelAtPeriodEnd: it.CancelAtEnd, PaymentFailedAt: it.PaymentFailed,
+    }, nil
+}
+
+// ParentForCustomer resolves a Stripe customer ID to the parent it belongs to.
+func (r *DynamoRepo) ParentForCustomer(ctx context.Context, customerID string) (string, error) {
+    it, err := r.getMeta(ctx, "CUSTOMER", customerID)
+    if err != nil { return "", err }
+    if it == nil { return "", apperr.New(apperr.NotFound, "unknown customer") }
+    return it.ParentID, nil
+}
+
+// LinkCustomer records the Stripe customer (and subscription, if known) of a
+// completed checkout.
+func (r *DynamoRepo) LinkCustomer(ctx context.Context, parentID, customerID, subscriptionID string) error {
+    g2pk, g2sk := gsi2Key("CUSTOMER", customerID)
+    expr := "SET #T = :t, ParentID = :p, CustomerID = :c, GSI2PK = :g2pk, GSI2SK = :g2sk"
+    vals := map[string]types.AttributeValue{
+        ":t":    &types.AttributeValueMemberS{Value: "Billing"},
+        ":p":    &types.AttributeValueMemberS{Value: parentID},
+        ":c":    &types.AttributeValueMemberS{Value: customerID},
+        ":g2pk": &types.AttributeValueMemberS{Value: g2pk},
+        ":g2sk": &types.AttributeValueMemberS{Value: g2sk},
+    }
+    if subscriptionID != "" {
+        expr += ", SubscriptionID = :s"
+        vals[":s"] = &types.AttributeValueMemberS{Value: subscriptionID}
+    }
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       billingKey(parentID),
+        UpdateExpression:          aws.String(expr),
+        ExpressionAttributeNames:  map[string]string{"#T": "Type"},
+        ExpressionAttributeValues: vals,
+    })
+    return err
+}
+
+// ApplySubscription stores subscription state from a Stripe event created at
+// at. It reports false when a later event was already applied. A healthy
+// status clears an earlier payment failure.
+func (r *DynamoRepo) ApplySubscription(ctx context.Context, parentID string, b Billing, at time.Time) (bool, error) {
+    g2pk, g2sk := gsi2Key("CUSTOMER", b.CustomerID)
+    expr := "SET #T = :t, ParentID = :p, CustomerID = :c, SubscriptionID = :s, #S = :st, CancelAtPeriodEnd = :cancel, BillingEventAt = :at, GSI2PK = :g2pk, GSI2SK = :g2sk"
+    vals := map[string]types.AttributeValue{
+        ":t":      &types.AttributeValueMemberS{Value: "Billing"},
+        ":p":      &types.AttributeValueMemberS{Value: parentID},
+        ":c":      &types.AttributeValueMemberS{Value: b.CustomerID},
+        ":s":      &types.AttributeValueMemberS{Value: b.SubscriptionID},
+        ":st":     &types.AttributeValueMemberS{Value: b.Status},
+        ":cancel": &types.AttributeValueMemberBOOL{Value: b.CancelAtPeriodEnd},
+        ":at":     &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", at.Unix())},
+        ":g2pk":   &types.AttributeValueMemberS{Value: g2pk},
+        ":g2sk":   &types.AttributeValueMemberS{Value: g2sk},
+    }
+    var remove []string
+    if b.CurrentPeriodEnd != nil {
+        expr += ", PeriodEnd = :end"
+        vals[":end"] = &types.AttributeValueMemberS{Value: *b.CurrentPeriodEnd}
+    } else {
+        remove = append(remove, "PeriodEnd")
+    }
+    if b.Status == "active" || b.Status == "trialing" { remove = append(remove, "PaymentFailedAt") }
+    if len(remove) > 0 { expr += " REMOVE " + strings.Join(remove, ", ") }
+    return r.applyBillingEvent(ctx, parentID, expr, vals)
+}
+
+// RecordPaymentFailure marks the subscription past due after a failed invoice
+// payment at at, unless a later event was already applied.
+func (r *DynamoRepo) RecordPaymentFailure(ctx context.Context, parentID string, at time.Time) (bool, error) {
+    return r.applyBillingEvent(ctx, parentID, "SET #T = :t, ParentID = :p, #S = :st, PaymentFailedAt = :failed, BillingEventAt = :at", map[string]types.AttributeValue{
+        ":t":      &types.AttributeValueMemberS{Value: "Billing"},
+        ":p":      &types.AttributeValueMemberS{Value: parentID},
+        ":st":     &types.AttributeValueMemberS{Value: "past_due"},
+        ":failed": &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339)},
+        ":at":     &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", at.Unix())},
+    })
+}
+
+func (r *DynamoRepo) applyBillingEvent(ctx context.Context, parentID, expr string, vals map[string]types.AttributeValue) (bool, error) {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       billingKey(parentID),
+        UpdateExpression:          aws.String(expr),
+        ConditionExpression:       aws.String("attribute_not_exists(BillingEventAt) OR BillingEventAt <= :at"),
+        ExpressionAttributeNames:  map[string]string{"#T": "Type", "#S": "Status"},
+        ExpressionAttributeValues: vals,
+    })
+    if err != nil {
+        if conditionFailed(err) { return false, nil }
+        return false, err
+    }
+    return true, nil
+}
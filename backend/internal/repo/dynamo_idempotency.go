@@ -0,0 +1,83 @@
+package repo
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+)
+
+// Idempotency
+func (r *DynamoRepo) idemKey(key string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkIdem(key)},
+        "SK": &types.AttributeValueMemberS{Value: "IDEM"},
+    }
+}
+
+func (r *DynamoRepo) ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, lease time.Duration) (*IdempotencyRecord, bool, error) {
+    now := time.Now()
+    claim := uuid.NewString()
+    it := item{PK: pkIdem(key), SK: "IDEM", Type: "Idempotency", Fingerprint: fingerprint, Status: "IN_PROGRESS", Created: NowRFC3339(), ExpiresAt: now.Add(lease).Unix(), Claim: claim}
+    av, _ := attributevalue.MarshalMap(it)
+    // TTL deletion is lazy, so an expired record may still be present and is
+    // overwritten; this is also how a retry takes over the lease of a request
+    // that died before completing.
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{
+        TableName:           aws.String(r.Table),
+        Item:                av,
+        ConditionExpression: aws.String("attribute_not_exists(PK) OR ExpiresAt < :now"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":now": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Unix())},
+        },
+    })
+    if err == nil { return &IdempotencyRecord{Fingerprint: fingerprint, Claim: claim}, true, nil }
+    if !conditionFailed(err) { return nil, false, err }
+
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: r.idemKey(key), ConsistentRead: aws.Bool(true)})
+    if err != nil { return nil, false, err }
+    if out.Item == nil {
+        // Released between our write and read; let the caller retry.
+        return &IdempotencyRecord{Fingerprint: fingerprint}, false, nil
+    }
+    var existing item
+    if err := attributevalue.UnmarshalMap(out.Item, &existing); err != nil { return nil, false, err }
+    return &IdempotencyRecord{Fingerprint: existing.Fingerprint, Response: []byte(existing.Response)}, false, nil
+}
+
+// CompleteIdempotencyKey stores the response and keeps it for ttl.
+func (r *DynamoRepo) CompleteIdempotencyKey(ctx context.Context, key, claim string, response []byte, ttl time.Duration) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                aws.String(r.Table),
+        Key:                      r.idemKey(key),
+        UpdateExpression:         aws.String("SET #S = :s, #R = :r, ExpiresAt = :exp"),
+        ConditionExpression:      aws.String("Claim = :c"),
+        ExpressionAttributeNames: map[string]string{"#S": "Status", "#R": "Response"},
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":s":   &types.AttributeValueMemberS{Value: "COMPLETED"},
+            ":r":   &types.AttributeValueMemberS{Value: string(response)},
+            ":exp": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().Add(ttl).Unix())},
+            ":c":   &types.AttributeValueMemberS{Value: claim},
+        },
+    })
+    if conditionFailed(err) { return nil }
+    return err
+}
+
+func (r *DynamoRepo) ReleaseIdempotencyKey(ctx context.Context, key, claim string) error {
+    _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 r.idemKey(key),
+        ConditionExpression: aws.String("Claim = :c"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":c": &types.AttributeValueMemberS{Value: claim},
+        },
+    })
+    if conditionFailed(err) { return nil }
+    return err
+}
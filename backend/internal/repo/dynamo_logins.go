@@ -0,0 +1,41 @@
+package repo
+
+import (
+    "context"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// Parent logins, keyed by normalized email
+func pkLogin(email string) string { return "LOGIN#" + email }
+
+const skLogin = "LOGIN"
+
+func (r *DynamoRepo) CreateLogin(ctx context.Context, email, parentID, passwordHash string) error {
+    it := item{PK: pkLogin(email), SK: skLogin, Type: "Login", ParentID: parentID, Email: email, PasswordHash: passwordHash, Created: NowRFC3339()}
+    av, _ := attributevalue.MarshalMap(it)
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")})
+    if conditionFailed(err) { return apperr.New(apperr.Conflict, "an account with this email already exists") }
+    return err
+}
+
+func (r *DynamoRepo) GetLogin(ctx context.Context, email string) (*Login, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{
+        TableName: aws.String(r.Table),
+        Key: map[string]types.AttributeValue{
+            "PK": &types.AttributeValueMemberS{Value: pkLogin(email)},
+            "SK": &types.AttributeValueMemberS{Value: skLogin},
+        },
+        ConsistentRead: aws.Bool(true),
+    })
+    if err != nil { return nil, err }
+    if out.Item == nil { return nil, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return &Login{ParentID: it.ParentID, PasswordHash: it.PasswordHash}, nil
+}
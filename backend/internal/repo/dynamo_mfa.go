@@ -0,0 +1,110 @@
+package repo
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/internal/apperr"
+)
+
+// MFA
+func (r *DynamoRepo) mfaKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skMFA},
+    }
+}
+
+func (r *DynamoRepo) GetMFA(ctx context.Context, parentID string) (*MFAState, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: r.mfaKey(parentID), ConsistentRead: aws.Bool(true)})
+    if err != nil { return nil, err }
+    if out.Item == nil { return &MFAState{}, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return &MFAState{Secret: it.TOTPSecret, EnabledAt: it.MFAOn, RecoveryHashes: it.Recovery}, nil
+}
+
+// SetPendingTOTP stores a fresh secret; re-enrolling is refused once MFA is enabled.
+func (r *DynamoRepo) SetPendingTOTP(ctx context.Context, parentID, secret string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                aws.String(r.Table),
+        Key:                      r.mfaKey(parentID),
+        UpdateExpression:         aws.String("SET #T = :t, TOTPSecret = :s, ParentID = :p REMOVE LastTOTPStep"),
+        ConditionExpression:      aws.String("attribute_not_exists(MFAEnabledAt)"),
+        ExpressionAttributeNames: map[string]string{"#T": "Type"},
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":t": &types.AttributeValueMemberS{Value: "MFA"},
+            ":s": &types.AttributeValueMemberS{Value: secret},
+            ":p": &types.AttributeValueMemberS{Value: parentID},
+        },
+    })
+    if conditionFailed(err) { return apperr.New(apperr.Validation, "two-factor authentication is already enabled") }
+    return err
+}
+
+func (r *DynamoRepo) EnableMFA(ctx context.Context, parentID string, recoveryHashes []string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 r.mfaKey(parentID),
+        UpdateExpression:    aws.String("SET MFAEnabledAt = :d, RecoveryCodes = :rc"),
+        ConditionExpression: aws.String("attribute_exists(TOTPSecret) AND attribute_not_exists(MFAEnabledAt)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":d":  &types.AttributeValueMemberS{Value: NowRFC3339()},
+            ":rc": &types.AttributeValueMemberSS{Value: recoveryHashes},
+        },
+    })
+    if conditionFailed(err) { return apperr.New(apperr.Validation, "no pending two-factor enrollment") }
+    return err
+}
+
+func (r *DynamoRepo) ReplaceRecoveryCodes(ctx context.Context, parentID string, recoveryHashes []string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 r.mfaKey(parentID),
+        UpdateExpression:    aws.String("SET RecoveryCodes = :rc"),
+        ConditionExpression: aws.String("attribute_exists(MFAEnabledAt)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":rc": &types.AttributeValueMemberSS{Value: recoveryHashes},
+        },
+    })
+    return err
+}
+
+// UseTOTPStep records the last accepted time step so a code cannot be replayed.
+func (r *DynamoRepo) UseTOTPStep(ctx context.Context, parentID string, step int64) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 r.mfaKey(parentID),
+        UpdateExpression:    aws.String("SET LastTOTPStep = :st"),
+        ConditionExpression: aws.String("attribute_exists(TOTPSecret) AND (attribute_not_exists(LastTOTPStep) OR LastTOTPStep < :st)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":st": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", step)},
+        },
+    })
+    return err
+}
+
+// UseRecoveryCode consumes a recovery code; it fails if the code was already used.
+func (r *DynamoRepo) UseRecoveryCode(ctx context.Context, parentID, hash string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 r.mfaKey(parentID),
+        UpdateExpression:    aws.String("DELETE RecoveryCodes :rc"),
+        ConditionExpression: aws.String("contains(RecoveryCodes, :h)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":rc": &types.AttributeValueMemberSS{Value: []string{hash}},
+            ":h":  &types.AttributeValueMemberS{Value: hash},
+        },
+    })
+    return err
+}
+
+func (r *DynamoRepo) DisableMFA(ctx context.Context, parentID string) error {
+    _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: r.mfaKey(parentID)})
+    return err
+}
@@ -0,0 +1,172 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/graph/model"
+)
+
+// Preferences live under the parent; households with an address that want the
+// weekly digest are also indexed in GSI1 so the scheduler can list them.
+const (
+    skNotify         = "NOTIFY"
+    gsi1DigestWeekly = "DIGEST#WEEKLY"
+)
+
+// Notification topics stored in the Topics set.
+const (
+    topicChoreSubmitted = "CHORE_SUBMITTED"
+    topicItemPurchased  = "ITEM_PURCHASED"
+    topicWeeklyDigest   = "WEEKLY_DIGEST"
+)
+
+func notifyKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skNotify},
+    }
+}
+
+// DefaultNotificationPreferences applies until a parent saves their own:
+// everything on, but nothing is sent before an address is set.
+func DefaultNotificationPreferences(parentID string) *model.NotificationPreferences {
+    return &model.NotificationPreferences{ParentID: parentID, ChoreSubmitted: true, ItemPurchased: true, WeeklyDigest: true}
+}
+
+func (r *DynamoRepo) GetNotificationPreferences(ctx context.Context, parentID string) (*model.NotificationPreferences, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: notifyKey(parentID)})
+    if err != nil { return nil, err }
+    if out.Item == nil { return DefaultNotificationPreferences(parentID), nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return toNotificationPreferences(it), nil
+}
+
+// SaveNotificationPreferences replaces the preferences but keeps the record of
+// the last digest sent, so toggling the digest cannot cause a second one.
+func (r *DynamoRepo) SaveNotificationPreferences(ctx context.Context, p *model.NotificationPreferences) error {
+    set := []string{"#T = :t", "ParentID = :p"}
+    var remove []string
+    vals := map[string]types.AttributeValue{
+        ":t": &types.AttributeValueMemberS{Value: "NotificationPreferences"},
+        ":p": &types.AttributeValueMemberS{Value: p.ParentID},
+    }
+    if p.Email != nil {
+        set = append(set, "Email = :e")
+        vals[":e"] = &types.AttributeValueMemberS{Value: *p.Email}
+    } else {
+        remove = append(remove, "Email")
+    }
+    if topics := notificationTopics(p); len(topics) > 0 {
+        set = append(set, "Topics = :topics")
+        vals[":topics"] = &types.AttributeValueMemberSS{Value: topics}
+    } else {
+        remove = append(remove, "Topics")
+    }
+    if p.WeeklyDigest && p.Email != nil {
+        set = append(set, "GSI1PK = :g1pk", "GSI1SK = :g1sk")
+        vals[":g1pk"] = &types.AttributeValueMemberS{Value: gsi1DigestWeekly}
+        vals[":g1sk"] = &types.AttributeValueMemberS{Value: p.ParentID}
+    } else {
+        remove = append(remove, "GSI1PK", "GSI1SK")
+    }
+    expr := "SET " + strings.Join(set, ", ")
+    if len(remove) > 0 { expr += " REMOVE " + strings.Join(remove, ", ") }
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       notifyKey(p.ParentID),
+        UpdateExpression:          aws.String(expr),
+        ExpressionAttributeNames:  map[string]string{"#T": "Type"},
+        ExpressionAttributeValues: vals,
+    })
+    return err
+}
+
+// DigestRecipients lists households with an address that want the weekly digest.
+func (r *DynamoRepo) DigestRecipients(ctx context.Context) ([]*model.NotificationPreferences, error) {
+    pages := dynamodb.NewQueryPaginator(r.DB, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        KeyConditionExpression: aws.String("GSI1PK = :pk"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: gsi1DigestWeekly},
+        },
+    })
+    var res []*model.NotificationPreferences
+    for pages.HasMorePages() {
+        out, err := pages.NextPage(ctx)
+        if err != nil { return nil, err }
+        for _, m := range out.Items {
+            var it item
+            if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+            res = append(res, toNotificationPreferences(it))
+        }
+    }
+    return res, nil
+}
+
+// ClaimDigest records that the digest for week (its Monday, YYYY-MM-DD) is
+// being sent. It reports false when this or a later week was already claimed,
+// so concurrent schedulers send each digest once.
+func (r *DynamoRepo) ClaimDigest(ctx context.Context, parentID, week string) (bool, error) {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 notifyKey(parentID),
+        UpdateExpression:    aws.String("SET DigestWeek = :w"),
+        ConditionExpression: aws.String("attribute_exists(PK) AND (attribute_not_exists(DigestWeek) OR DigestWeek < :w)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":w": &types.AttributeValueMemberS{Value: week},
+        },
+    })
+    if err != nil {
+        if conditionFailed(err) { return false, nil }
+        return false, err
+    }
+    return true, nil
+}
+
+// ReleaseDigest undoes ClaimDigest for week after the digest could not be
+// sent; a later claim is left alone.
+func (r *DynamoRepo) ReleaseDigest(ctx context.Context, parentID, week string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 notifyKey(parentID),
+        UpdateExpression:    aws.String("REMOVE DigestWeek"),
+        ConditionExpression: aws.String("DigestWeek = :w"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":w": &types.AttributeValueMemberS{Value: week},
+        },
+    })
+    if conditionFailed(err) { return nil }
+    return err
+}
+
+func notificationTopics(p *model.NotificationPreferences) []string {
+    var topics []string
+    if p.ChoreSubmitted { topics = append(topics, topicChoreSubmitted) }
+    if p.ItemPurchased { topics = append(topics, topicItemPurchased) }
+    if p.WeeklyDigest { topics = append(topics, topicWeeklyDigest) }
+    return topics
+}
+
+func toNotificationPreferences(it item) *model.NotificationPreferences {
+    p := &model.NotificationPreferences{ParentID: it.ParentID}
+    if it.Email != "" { p.Email = &it.Email }
+    for _, t := range it.Topics {
+        switch t {
+        case topicChoreSubmitted:
+            p.ChoreSubmitted = true
+        case topicItemPurchased:
+            p.ItemPurchased = true
+        case topicWeeklyDigest:
+            p.WeeklyDigest = true
+        }
+    }
+    return p
+}
@@ -0,0 +1,163 @@
+package repo
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/internal/events"
+)
+
+// Outbox items are written in the same transaction as the change they
+// describe and indexed in GSI1 until a dispatcher has published them. Entries
+// that exhausted their attempts move to the dead-letter partition instead.
+const (
+    gsi1OutboxPending = "OUTBOX#PENDING"
+    gsi1OutboxDead    = "OUTBOX#DEAD"
+    outboxRetention   = 7 * 24 * time.Hour
+)
+
+func pkOutbox(eventID string) string { return "OUTBOX#" + eventID }
+
+func outboxKey(eventID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkOutbox(eventID)},
+        "SK": &types.AttributeValueMemberS{Value: "OUTBOX"},
+    }
+}
+
+// outboxPut returns the transaction item recording e.
+func (r *DynamoRepo) outboxPut(e events.Event) (types.TransactWriteItem, error) {
+    payload, err := json.Marshal(e)
+    if err != nil { return types.TransactWriteItem{}, err }
+    it := item{
+        PK: pkOutbox(e.ID), SK: "OUTBOX", Type: "Outbox", EventType: string(e.Type), ParentID: e.ParentID, ChildID: e.ChildID,
+        Payload: string(payload), Status: "PENDING", Created: e.At, NextAt: &e.At,
+        GSI1PK: gsi1OutboxPending, GSI1SK: e.At + "#" + e.ID,
+    }
+    av, err := attributevalue.MarshalMap(it)
+    if err != nil { return types.TransactWriteItem{}, err }
+    return types.TransactWriteItem{Put: &types.Put{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}}, nil
+}
+
+// transact writes items together with outbox records for evs, then notifies
+// OnCommit so a dispatcher can publish without waiting for its next poll.
+func (r *DynamoRepo) transact(ctx context.Context, items []types.TransactWriteItem, evs ...events.Event) error {
+    for _, e := range evs {
+        put, err := r.outboxPut(e)
+        if err != nil { return err }
+        items = append(items, put)
+    }
+    if _, err := r.DB.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items}); err != nil {
+        return err
+    }
+    if len(evs) > 0 && r.OnCommit != nil {
+        r.OnCommit()
+    }
+    return nil
+}
+
+func (r *DynamoRepo) PendingOutbox(ctx context.Context, now time.Time, limit int) ([]*OutboxEntry, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK <= :now"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk":  &types.AttributeValueMemberS{Value: gsi1OutboxPending},
+            ":now": &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339) + "$"},
+        },
+        Limit: aws.Int32(int32(limit)),
+    })
+    if err != nil { return nil, err }
+    res := make([]*OutboxEntry, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        var e events.Event
+        if err := json.Unmarshal([]byte(it.Payload), &e); err != nil { return nil, fmt.Errorf("outbox %s: %w", it.PK, err) }
+        res = append(res, &OutboxEntry{Event: e, Attempts: it.Attempts, lease: it.GSI1SK})
+    }
+    return res, nil
+}
+
+// LeaseOutbox hides a pending entry from other dispatchers until until and
+// counts the attempt. It reports false when another dispatcher leased or
+// delivered it first.
+func (r *DynamoRepo) LeaseOutbox(ctx context.Context, o *OutboxEntry, until time.Time) (bool, error) {
+    next := until.UTC().Format(time.RFC3339)
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 outboxKey(o.Event.ID),
+        UpdateExpression:    aws.String("SET NextAttemptAt = :next, GSI1SK = :sk ADD Attempts :one"),
+        ConditionExpression: aws.String("GSI1SK = :old"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":next": &types.AttributeValueMemberS{Value: next},
+            ":sk":   &types.AttributeValueMemberS{Value: next + "#" + o.Event.ID},
+            ":old":  &types.AttributeValueMemberS{Value: o.lease},
+            ":one":  num(1),
+        },
+    })
+    if err != nil {
+        if conditionFailed(err) { return false, nil }
+        return false, err
+    }
+    o.lease = next + "#" + o.Event.ID
+    o.Attempts++
+    return true, nil
+}
+
+// FailOutbox is a no-op when the lease has passed to another dispatcher.
+// Dead entries are kept, without expiry, under GSI1PK OUTBOX#DEAD.
+func (r *DynamoRepo) FailOutbox(ctx context.Context, o *OutboxEntry, lastErr string, next *time.Time) error {
+    vals := map[string]types.AttributeValue{
+        ":old": &types.AttributeValueMemberS{Value: o.lease},
+        ":err": &types.AttributeValueMemberS{Value: lastErr},
+    }
+    var expr string
+    if next != nil {
+        at := next.UTC().Format(time.RFC3339)
+        expr = "SET LastError = :err, NextAttemptAt = :next, GSI1SK = :sk"
+        vals[":next"] = &types.AttributeValueMemberS{Value: at}
+        vals[":sk"] = &types.AttributeValueMemberS{Value: at + "#" + o.Event.ID}
+    } else {
+        expr = "SET #S = :dead, LastError = :err, GSI1PK = :pk, GSI1SK = :sk REMOVE NextAttemptAt"
+        vals[":dead"] = &types.AttributeValueMemberS{Value: "DEAD"}
+        vals[":pk"] = &types.AttributeValueMemberS{Value: gsi1OutboxDead}
+        vals[":sk"] = &types.AttributeValueMemberS{Value: NowRFC3339() + "#" + o.Event.ID}
+    }
+    in := &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       outboxKey(o.Event.ID),
+        UpdateExpression:          aws.String(expr),
+        ConditionExpression:       aws.String("GSI1SK = :old"),
+        ExpressionAttributeValues: vals,
+    }
+    if next == nil { in.ExpressionAttributeNames = map[string]string{"#S": "Status"} }
+    _, err := r.DB.UpdateItem(ctx, in)
+    if conditionFailed(err) { return nil }
+    return err
+}
+
+// MarkOutboxDelivered removes the entry from the pending index; the record
+// itself expires after outboxRetention.
+func (r *DynamoRepo) MarkOutboxDelivered(ctx context.Context, eventID string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                aws.String(r.Table),
+        Key:                      outboxKey(eventID),
+        UpdateExpression:         aws.String("SET #S = :s, DeliveredAt = :d, ExpiresAt = :exp REMOVE GSI1PK, GSI1SK, NextAttemptAt"),
+        ConditionExpression:      aws.String("attribute_exists(PK)"),
+        ExpressionAttributeNames: map[string]string{"#S": "Status"},
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":s":   &types.AttributeValueMemberS{Value: "DELIVERED"},
+            ":d":   &types.AttributeValueMemberS{Value: NowRFC3339()},
+            ":exp": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().Add(outboxRetention).Unix())},
+        },
+    })
+    return err
+}
@@ -0,0 +1,226 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/events"
+)
+
+// The exchange rate lives under the parent. Payouts live under the child,
+// oldest first by SK, and are indexed per household in GSI1 and by ID in GSI2.
+const skExchangeRate = "EXCHANGE_RATE"
+
+func skPayout(at, payoutID string) string { return "PAYOUT#" + at + "#" + payoutID }
+func gsi1Payouts(parentID string) string  { return "PAYOUTS#" + parentID }
+
+func exchangeRateKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skExchangeRate},
+    }
+}
+
+func (r *DynamoRepo) GetExchangeRate(ctx context.Context, parentID string) (*model.ExchangeRate, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: exchangeRateKey(parentID)})
+    if err != nil { return nil, err }
+    if out.Item == nil { return nil, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return &model.ExchangeRate{Currency: it.Currency, Gold: it.RateGold, MinorUnits: it.RateMinor}, nil
+}
+
+// SetExchangeRate replaces the household's rate; nil removes it.
+func (r *DynamoRepo) SetExchangeRate(ctx context.Context, parentID string, rate *model.ExchangeRate) error {
+    if rate == nil {
+        _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: exchangeRateKey(parentID)})
+        return err
+    }
+    av, _ := attributevalue.MarshalMap(item{
+        PK: pkParent(parentID), SK: skExchangeRate, Type: "ExchangeRate",
+        ParentID: parentID, Currency: rate.Currency, RateGold: rate.Gold, RateMinor: rate.MinorUnits, Created: NowRFC3339(),
+    })
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av})
+    return err
+}
+
+// RequestPayout takes gold from the child's balance and records a payout of
+// amountMinor for it. Like PurchaseItem the balance is only written if it is
+// unchanged since it was read, so it can never go negative.
+func (r *DynamoRepo) RequestPayout(ctx context.Context, childID string, gold, amountMinor int, currency string) (*model.Payout, error) {
+    for attempt := 1; ; attempt++ {
+        ch, err := r.getMeta(ctx, "CHILD", childID)
+        if err != nil { return nil, err }
+        if ch == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+        if ch.Gold < gold { return nil, apperr.New(apperr.InsufficientFunds, "not enough gold") }
+
+        p := &model.Payout{
+            ID: uuid.NewString(), ParentID: ch.ParentID, ChildID: childID, Gold: gold, AmountMinor: amountMinor,
+            Currency: currency, Status: model.PayoutStatusRequested, RequestedAt: NowRFC3339(),
+        }
+        rec := item{
+            PK: pkChild(childID), SK: skPayout(p.RequestedAt, p.ID), Type: "Payout",
+            ParentID: ch.ParentID, ChildID: childID, Gold: gold, AmountMinor: amountMinor, Currency: currency,
+            Status: string(p.Status), Created: p.RequestedAt,
+            GSI1PK: gsi1Payouts(ch.ParentID), GSI1SK: p.RequestedAt + "#" + p.ID,
+        }
+        rec.GSI2PK, rec.GSI2SK = gsi2Key("PAYOUT", p.ID)
+        av, _ := attributevalue.MarshalMap(rec)
+        e := events.New(events.PayoutRequested, ch.ParentID, childID)
+        e.Payout = p
+        e.Child = &model.Child{ID: childID, ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP, Gold: ch.Gold - gold, CreatedAt: createdAt(*ch)}
+        err = r.transact(ctx, []types.TransactWriteItem{{Update: &types.Update{
+            TableName:                 aws.String(r.Table),
+            Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: ch.PK}, "SK": &types.AttributeValueMemberS{Value: ch.SK}},
+            UpdateExpression:          aws.String("ADD Gold :delta"),
+            ConditionExpression:       aws.String(unchanged("Gold", ":g0", ch.Gold)),
+            ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(-gold), ":g0": num(ch.Gold)},
+        }}, {Put: &types.Put{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}}}, e)
+        switch {
+        case err == nil:
+            return p, nil
+        case txConditionFailed(err, 0) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 0):
+            return nil, apperr.New(apperr.Conflict, "balance changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// payoutMoves lists the statuses a payout may move to from each status.
+var payoutMoves = map[model.PayoutStatus][]model.PayoutStatus{
+    model.PayoutStatusRequested: {model.PayoutStatusApproved, model.PayoutStatusPaid, model.PayoutStatusDeclined},
+    model.PayoutStatusApproved:  {model.PayoutStatusPaid, model.PayoutStatusDeclined},
+}
+
+// UpdatePayout moves a payout of parentID's household to status to. Declining
+// refunds the gold to the child in the same transaction, guarded like
+// RequestPayout; paying straight from REQUESTED also counts as approval.
+func (r *DynamoRepo) UpdatePayout(ctx context.Context, parentID, payoutID string, to model.PayoutStatus) (*model.Payout, error) {
+    for attempt := 1; ; attempt++ {
+        it, err := r.getMeta(ctx, "PAYOUT", payoutID)
+        if err != nil { return nil, err }
+        if it == nil || it.ParentID != parentID { return nil, apperr.New(apperr.NotFound, "payout not found") }
+        p := toPayout(*it)
+        allowed := false
+        for _, s := range payoutMoves[p.Status] {
+            allowed = allowed || s == to
+        }
+        if !allowed { return nil, apperr.New(apperr.Conflict, "payout is %s", strings.ToLower(string(p.Status))) }
+
+        now := NowRFC3339()
+        expr := "SET #S = :to"
+        vals := map[string]types.AttributeValue{
+            ":to":   &types.AttributeValueMemberS{Value: string(to)},
+            ":from": &types.AttributeValueMemberS{Value: string(p.Status)},
+            ":now":  &types.AttributeValueMemberS{Value: now},
+        }
+        if p.DecidedAt == nil {
+            expr += ", DecidedAt = :now"
+            p.DecidedAt = &now
+        }
+        if to == model.PayoutStatusPaid {
+            expr += ", PaidAt = :now"
+            p.PaidAt = &now
+        }
+        p.Status = to
+        items := []types.TransactWriteItem{{Update: &types.Update{
+            TableName:                 aws.String(r.Table),
+            Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
+            UpdateExpression:          aws.String(expr),
+            ConditionExpression:       aws.String("#S = :from"),
+            ExpressionAttributeNames:  map[string]string{"#S": "Status"},
+            ExpressionAttributeValues: vals,
+        }}}
+        var evs []events.Event
+        switch to {
+        case model.PayoutStatusPaid:
+            e := events.New(events.PayoutPaid, parentID, p.ChildID)
+            e.Payout = p
+            evs = append(evs, e)
+        case model.PayoutStatusDeclined:
+            ch, err := r.getMeta(ctx, "CHILD", p.ChildID)
+            if err != nil { return nil, err }
+            if ch == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+            items = append(items, types.TransactWriteItem{Update: &types.Update{
+                TableName:                 aws.String(r.Table),
+                Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: ch.PK}, "SK": &types.AttributeValueMemberS{Value: ch.SK}},
+                UpdateExpression:          aws.String("ADD Gold :delta"),
+                ConditionExpression:       aws.String(unchanged("Gold", ":g0", ch.Gold)),
+                ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(p.Gold), ":g0": num(ch.Gold)},
+            }})
+            e := events.New(events.PayoutDeclined, parentID, p.ChildID)
+            e.Payout = p
+            e.Child = &model.Child{ID: p.ChildID, ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP, Gold: ch.Gold + p.Gold, CreatedAt: createdAt(*ch)}
+            evs = append(evs, e)
+        }
+        err = r.transact(ctx, items, evs...)
+        switch {
+        case err == nil:
+            return p, nil
+        case (txConditionFailed(err, 0) || txConditionFailed(err, 1)) && attempt < balanceRetries:
+            // Re-read: a concurrent decision now reports CONFLICT above.
+            continue
+        case txConditionFailed(err, 0) || txConditionFailed(err, 1):
+            return nil, apperr.New(apperr.Conflict, "payout changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// ListPayouts returns the household's payouts, newest first.
+func (r *DynamoRepo) ListPayouts(ctx context.Context, parentID string) ([]*model.Payout, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        KeyConditionExpression: aws.String("GSI1PK = :pk"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: gsi1Payouts(parentID)},
+        },
+        ScanIndexForward: aws.Bool(false),
+    })
+    if err != nil { return nil, err }
+    return toPayouts(out.Items)
+}
+
+// ListChildPayouts returns the child's payouts, newest first.
+func (r *DynamoRepo) ListChildPayouts(ctx context.Context, childID string) ([]*model.Payout, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkChild(childID)},
+            ":sk": &types.AttributeValueMemberS{Value: "PAYOUT#"},
+        },
+        ScanIndexForward: aws.Bool(false),
+    })
+    if err != nil { return nil, err }
+    return toPayouts(out.Items)
+}
+
+func toPayouts(items []map[string]types.AttributeValue) ([]*model.Payout, error) {
+    res := make([]*model.Payout, 0, len(items))
+    for _, m := range items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toPayout(it))
+    }
+    return res, nil
+}
+
+func toPayout(it item) *model.Payout {
+    return &model.Payout{
+        ID: it.SK[strings.LastIndex(it.SK, "#")+1:], ParentID: it.ParentID, ChildID: it.ChildID,
+        Gold: it.Gold, AmountMinor: it.AmountMinor, Currency: it.Currency, Status: model.PayoutStatus(it.Status),
+        RequestedAt: it.Created, DecidedAt: it.DecidedAt, PaidAt: it.PaidAt,
+    }
+}
@@ -0,0 +1,107 @@
+package repo
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// Push subscriptions and quiet hours live under the child. A subscription's ID
+// derives from its endpoint, so a device that re-subscribes replaces its record.
+const skQuiet = "QUIET"
+
+func skPush(subID string) string { return "PUSH#" + subID }
+
+func pushSubscriptionID(endpoint string) string {
+    h := sha256.Sum256([]byte(endpoint))
+    return hex.EncodeToString(h[:16])
+}
+
+func childKey(childID, sk string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkChild(childID)},
+        "SK": &types.AttributeValueMemberS{Value: sk},
+    }
+}
+
+// SavePushSubscription stores the device's endpoint and keys, replacing an
+// earlier registration of the same endpoint.
+func (r *DynamoRepo) SavePushSubscription(ctx context.Context, parentID, childID, endpoint, p256dh, auth string) (*model.PushSubscription, error) {
+    sid := pushSubscriptionID(endpoint)
+    it := item{
+        PK: pkChild(childID), SK: skPush(sid), Type: "PushSubscription",
+        ParentID: parentID, ChildID: childID, URL: endpoint, P256dh: p256dh, PushAuth: auth, Created: NowRFC3339(),
+    }
+    av, _ := attributevalue.MarshalMap(it)
+    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av}); err != nil {
+        return nil, err
+    }
+    return toPushSubscription(it), nil
+}
+
+func (r *DynamoRepo) ListPushSubscriptions(ctx context.Context, childID string) ([]*PushTarget, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkChild(childID)},
+            ":sk": &types.AttributeValueMemberS{Value: "PUSH#"},
+        },
+    })
+    if err != nil { return nil, err }
+    res := make([]*PushTarget, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, &PushTarget{Subscription: toPushSubscription(it), P256dh: it.P256dh, Auth: it.PushAuth})
+    }
+    return res, nil
+}
+
+func (r *DynamoRepo) DeletePushSubscription(ctx context.Context, childID, subID string) (*model.PushSubscription, error) {
+    out, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+        TableName:    aws.String(r.Table),
+        Key:          childKey(childID, skPush(subID)),
+        ReturnValues: types.ReturnValueAllOld,
+    })
+    if err != nil { return nil, err }
+    if out.Attributes == nil { return nil, apperr.New(apperr.NotFound, "push subscription not found") }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &it); err != nil { return nil, err }
+    return toPushSubscription(it), nil
+}
+
+// GetQuietHours returns nil when the child has none.
+func (r *DynamoRepo) GetQuietHours(ctx context.Context, childID string) (*model.QuietHours, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: childKey(childID, skQuiet)})
+    if err != nil { return nil, err }
+    if out.Item == nil { return nil, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return &model.QuietHours{Start: it.QuietStart, End: it.QuietEnd, TimeZone: it.TimeZone}, nil
+}
+
+// SetQuietHours replaces the child's quiet hours; nil removes them.
+func (r *DynamoRepo) SetQuietHours(ctx context.Context, childID string, q *model.QuietHours) error {
+    if q == nil {
+        _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: childKey(childID, skQuiet)})
+        return err
+    }
+    it := item{PK: pkChild(childID), SK: skQuiet, Type: "QuietHours", ChildID: childID, QuietStart: q.Start, QuietEnd: q.End, TimeZone: q.TimeZone}
+    av, _ := attributevalue.MarshalMap(it)
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av})
+    return err
+}
+
+func toPushSubscription(it item) *model.PushSubscription {
+    return &model.PushSubscription{ID: strings.TrimPrefix(it.SK, "PUSH#"), ChildID: it.ChildID, Endpoint: it.URL, CreatedAt: it.Created}
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// The difficulty multipliers live under the parent.
+const skDifficultyMultipliers = "DIFFICULTY_MULTIPLIERS"
+
+func difficultyMultipliersKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skDifficultyMultipliers},
+    }
+}
+
+// GetDifficultyMultipliers returns nil when the household has none.
+func (r *DynamoRepo) GetDifficultyMultipliers(ctx context.Context, parentID string) (*model.DifficultyMultipliers, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: difficultyMultipliersKey(parentID)})
+    if err != nil || out.Item == nil { return nil, err }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return &model.DifficultyMultipliers{Easy: it.MultEasy, Medium: it.MultMedium, Hard: it.MultHard}, nil
+}
+
+// SetDifficultyMultipliers replaces the household's multipliers; nil removes them.
+func (r *DynamoRepo) SetDifficultyMultipliers(ctx context.Context, parentID string, m *model.DifficultyMultipliers) error {
+    if m == nil {
+        _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: difficultyMultipliersKey(parentID)})
+        return err
+    }
+    av, _ := attributevalue.MarshalMap(item{
+        PK: pkParent(parentID), SK: skDifficultyMultipliers, Type: "DifficultyMultipliers",
+        ParentID: parentID, MultEasy: m.Easy, MultMedium: m.Medium, MultHard: m.Hard,
+    })
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av})
+    return err
+}
+
+// UpdateQuestDetails replaces the quest's category, tags and difficulty.
+func (r *DynamoRepo) UpdateQuestDetails(ctx context.Context, questID string, in model.QuestDetailsInput) (*model.Quest, error) {
+    it, err := r.getMeta(ctx, "QUEST", questID)
+    if err != nil { return nil, err }
+    if it == nil { return nil, apperr.New(apperr.NotFound, "quest not found") }
+    set, remove := []string{}, []string{}
+    vals := map[string]types.AttributeValue{}
+    it.Category, it.Difficulty, it.Tags = "", "", in.Tags
+    if in.Category != nil {
+        it.Category = string(*in.Category)
+        set, vals[":c"] = append(set, "Category = :c"), &types.AttributeValueMemberS{Value: it.Category}
+    } else {
+        remove = append(remove, "Category")
+    }
+    if in.Difficulty != nil {
+        it.Difficulty = string(*in.Difficulty)
+        set, vals[":d"] = append(set, "Difficulty = :d"), &types.AttributeValueMemberS{Value: it.Difficulty}
+    } else {
+        remove = append(remove, "Difficulty")
+    }
+    if len(in.Tags) > 0 {
+        tags, _ := attributevalue.Marshal(in.Tags)
+        set, vals[":t"] = append(set, "Tags = :t"), tags
+    } else {
+        remove = append(remove, "Tags")
+    }
+    expr := ""
+    if len(set) > 0 { expr = "SET " + strings.Join(set, ", ") }
+    if len(remove) > 0 { expr += " REMOVE " + strings.Join(remove, ", ") }
+    input := &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
+        UpdateExpression:    aws.String(strings.TrimSpace(expr)),
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+    }
+    if len(vals) > 0 { input.ExpressionAttributeValues = vals }
+    if _, err := r.DB.UpdateItem(ctx, input); err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "quest not found") }
+        return nil, err
+    }
+    return toQuest(*it), nil
+}
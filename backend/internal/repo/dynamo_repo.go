@@ -13,11 +13,15 @@ import (
     "github.com/google/uuid"
 
     "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/events"
 )
 
 type DynamoRepo struct {
     DB    *dynamodb.Client
     Table string
+    // OnCommit, if set, is called after a transaction that wrote outbox events.
+    OnCommit func()
 }
 
 func NewDynamoRepo(db *dynamodb.Client, table string) *DynamoRepo {
@@ -47,8 +51,145 @@ type item struct {
     Status   string  `dynamodbav:"Status,omitempty"`
     Created  string  `dynamodbav:"CreatedAt,omitempty"`
     DoneAt   *string `dynamodbav:"CompletedAt,omitempty"`
+
+    // API keys
+    KeyHash  string   `dynamodbav:"KeyHash,omitempty"`
+    Prefix   string   `dynamodbav:"Prefix,omitempty"`
+    Scopes   []string `dynamodbav:"Scopes,omitempty"`
+    UsedAt   *string  `dynamodbav:"LastUsedAt,omitempty"`
+    Revoked  *string  `dynamodbav:"RevokedAt,omitempty"`
+
+    // Parent logins
+    PasswordHash string `dynamodbav:"PasswordHash,omitempty"`
+
+    // MFA
+    TOTPSecret string   `dynamodbav:"TOTPSecret,omitempty"`
+    MFAOn      *string  `dynamodbav:"MFAEnabledAt,omitempty"`
+    Recovery   []string `dynamodbav:"RecoveryCodes,omitempty,stringset"`
+
+    // Idempotency records; ExpiresAt is the table's TTL attribute (epoch seconds)
+    Fingerprint string `dynamodbav:"Fingerprint,omitempty"`
+    Response    string `dynamodbav:"Response,omitempty"`
+    ExpiresAt   int64  `dynamodbav:"ExpiresAt,omitempty"`
+    Claim       string `dynamodbav:"Claim,omitempty"`
+
+    // Webhooks and their delivery log
+    URL         string   `dynamodbav:"URL,omitempty"`
+    Events      []string `dynamodbav:"Events,omitempty"`
+    Secret      string   `dynamodbav:"Secret,omitempty"`
+    WebhookID   string   `dynamodbav:"WebhookID,omitempty"`
+    EventID     string   `dynamodbav:"EventID,omitempty"`
+    EventType   string   `dynamodbav:"EventType,omitempty"`
+    Payload     string   `dynamodbav:"Payload,omitempty"`
+    Attempts    int      `dynamodbav:"Attempts,omitempty"`
+    HTTPStatus  *int     `dynamodbav:"ResponseStatus,omitempty"`
+    LastError   *string  `dynamodbav:"LastError,omitempty"`
+    NextAt      *string  `dynamodbav:"NextAttemptAt,omitempty"`
+    DeliveredAt *string  `dynamodbav:"DeliveredAt,omitempty"`
+
+    // Notification preferences
+    Email      string   `dynamodbav:"Email,omitempty"`
+    Topics     []string `dynamodbav:"Topics,omitempty,stringset"`
+    DigestWeek string   `dynamodbav:"DigestWeek,omitempty"`
+
+    // Web Push subscriptions and quiet hours
+    P256dh     string `dynamodbav:"P256dh,omitempty"`
+    PushAuth   string `dynamodbav:"PushAuth,omitempty"`
+    QuietStart string `dynamodbav:"QuietStart,omitempty"`
+    QuietEnd   string `dynamodbav:"QuietEnd,omitempty"`
+    TimeZone   string `dynamodbav:"TimeZone,omitempty"`
+
+    // Stripe billing
+    CustomerID     string  `dynamodbav:"CustomerID,omitempty"`
+    SubscriptionID string  `dynamodbav:"SubscriptionID,omitempty"`
+    PeriodEnd      *string `dynamodbav:"PeriodEnd,omitempty"`
+    CancelAtEnd    bool    `dynamodbav:"CancelAtPeriodEnd,omitempty"`
+    PaymentFailed  *string `dynamodbav:"PaymentFailedAt,omitempty"`
+    BillingEventAt int64   `dynamodbav:"BillingEventAt,omitempty"`
+
+    // Quest schedules
+    Recurrence   string `dynamodbav:"Recurrence,omitempty"`
+    NextRunAt    string `dynamodbav:"NextRunAt,omitempty"`
+    LastAssignID string `dynamodbav:"LastAssignmentID,omitempty"`
+
+    // Allowance exchange rate and payouts
+    Currency    string  `dynamodbav:"Currency,omitempty"`
+    RateGold    int     `dynamodbav:"RateGold,omitempty"`
+    RateMinor   int     `dynamodbav:"RateMinor,omitempty"`
+    AmountMinor int     `dynamodbav:"AmountMinor,omitempty"`
+    DecidedAt   *string `dynamodbav:"DecidedAt,omitempty"`
+    PaidAt      *string `dynamodbav:"PaidAt,omitempty"`
+
+    // Savings goals
+    Target        int     `dynamodbav:"TargetGold,omitempty"`
+    Saved         int     `dynamodbav:"SavedGold,omitempty"`
+    Bonus         int     `dynamodbav:"BonusGold,omitempty"`
+    MatchDeposits int     `dynamodbav:"MatchDeposits,omitempty"`
+    EarlyWithdraw bool    `dynamodbav:"AllowEarlyWithdrawal,omitempty"`
+    RuleKind      string  `dynamodbav:"RuleKind,omitempty"`
+    RulePercent   int     `dynamodbav:"RulePercent,omitempty"`
+    RuleCap       *int    `dynamodbav:"RuleMaxGold,omitempty"`
+    BonusWeek     string  `dynamodbav:"BonusWeek,omitempty"`
+    ReachedAt     *string `dynamodbav:"ReachedAt,omitempty"`
+
+    // Gold transfers
+    ToChildID string  `dynamodbav:"ToChildID,omitempty"`
+    Note      *string `dynamodbav:"Note,omitempty"`
+    Threshold *int    `dynamodbav:"ApprovalThreshold,omitempty"`
+
+    // Spending limits and purchase requests
+    DailyGold  *int     `dynamodbav:"DailyGold,omitempty"`
+    WeeklyGold *int     `dynamodbav:"WeeklyGold,omitempty"`
+    ItemNames  []string `dynamodbav:"ItemNames,omitempty"`
+    HoldReason string   `dynamodbav:"HoldReason,omitempty"`
+
+    // Quest templates
+    MinAge int `dynamodbav:"MinAge,omitempty"`
+    MaxAge int `dynamodbav:"MaxAge,omitempty"`
+
+    // Quest categories, difficulty multipliers and what completions paid
+    Category    string   `dynamodbav:"Category,omitempty"`
+    Tags        []string `dynamodbav:"Tags,omitempty"`
+    Difficulty  string   `dynamodbav:"Difficulty,omitempty"`
+    MultEasy    int      `dynamodbav:"MultEasy,omitempty"`
+    MultMedium  int      `dynamodbav:"MultMedium,omitempty"`
+    MultHard    int      `dynamodbav:"MultHard,omitempty"`
+    XPAwarded   *int     `dynamodbav:"XPAwarded,omitempty"`
+    GoldAwarded *int     `dynamodbav:"GoldAwarded,omitempty"`
+
+    // Child birthdates (YYYY-MM-DD)
+    Birthdate *string `dynamodbav:"Birthdate,omitempty"`
 }
 
+// conditionFailed reports whether a single-item write was rejected by its ConditionExpression.
+func conditionFailed(err error) bool {
+    var ccf *types.ConditionalCheckFailedException
+    return errors.As(err, &ccf)
+}
+
+// txConditionFailed reports whether a transaction was cancelled because the
+// condition on its i-th item failed.
+func txConditionFailed(err error, i int) bool {
+    var tce *types.TransactionCanceledException
+    if !errors.As(err, &tce) || i >= len(tce.CancellationReasons) {
+        return false
+    }
+    return aws.ToString(tce.CancellationReasons[i].Code) == "ConditionalCheckFailed"
+}
+
+// balanceRetries bounds how often a balance change is retried when another
+// write changed the child between our read and the transaction.
+const balanceRetries = 3
+
+// unchanged is a condition that the number attr still equals the value bound
+// to placeholder; a missing attribute counts as 0.
+func unchanged(attr, placeholder string, n int) string {
+    if n == 0 { return "(attribute_not_exists(" + attr + ") OR " + attr + " = " + placeholder + ")" }
+    return attr + " = " + placeholder
+}
+
+func num(n int) types.AttributeValue { return &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", n)} }
+
 // Key builders
 func pkParent(parentID string) string { return "PARENT#" + parentID }
 func skChild(childID string) string  { return "CHILD#" + childID }
@@ -56,6 +197,12 @@ func skQuest(questID string) string  { return "QUEST#" + questID }
 func skReward(rewardID string) string { return "REWARD#" + rewardID }
 func pkChild(childID string) string  { return "CHILD#" + childID }
 func skAssign(assignID string) string { return "ASSIGN#" + assignID }
+func skAPIKey(keyID string) string { return "APIKEY#" + keyID }
+func skRedeem(rewardID string) string { return "REDEEM#" + rewardID }
+func skWebhook(webhookID string) string { return "WEBHOOK#" + webhookID }
+func skPurchase(at, purchaseID string) string { return "PURCHASE#" + at + "#" + purchaseID }
+const skMFA = "MFA"
+func pkIdem(key string) string { return "IDEM#" + key }
 func gsi2Key(tag, id string) (string, string) { return tag + "#" + id, "META" }
 
 // Children
@@ -63,7 +210,7 @@ func (r *DynamoRepo) CreateChild(ctx context.Context, in model.NewChild) (*model
     cid := uuid.NewString()
     it := item{
         PK: pkParent(in.ParentID), SK: skChild(cid), Type: "Child",
-        ParentID: in.ParentID, Name: in.Name, XP: 0, Gold: 0,
+        ParentID: in.ParentID, Name: in.Name, XP: 0, Gold: 0, Birthdate: in.Birthdate, Created: NowRFC3339(),
     }
     g2pk, g2sk := gsi2Key("CHILD", cid)
     it.GSI2PK, it.GSI2SK = g2pk, g2sk
@@ -71,7 +218,7 @@ func (r *DynamoRepo) CreateChild(ctx context.Context, in model.NewChild) (*model
     if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
         return nil, err
     }
-    return &model.Child{ID: cid, ParentID: in.ParentID, Name: in.Name, Xp: 0, Gold: 0}, nil
+    return toChild(it), nil
 }
 
 func (r *DynamoRepo) ListChildren(ctx context.Context, parentID string) ([]*model.Child, error) {
@@ -88,23 +235,90 @@ func (r *DynamoRepo) ListChildren(ctx context.Context, parentID string) ([]*mode
     for _, m := range out.Items {
         var it item
         if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
-        id := strings.TrimPrefix(it.SK, "CHILD#")
-        res = append(res, &model.Child{ID: id, ParentID: it.ParentID, Name: it.Name, Xp: it.XP, Gold: it.Gold})
+        res = append(res, toChild(it))
     }
     return res, nil
 }
 
+func (r *DynamoRepo) GetChild(ctx context.Context, childID string) (*model.Child, error) {
+    it, err := r.getMeta(ctx, "CHILD", childID)
+    if err != nil { return nil, err }
+    if it == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+    return toChild(*it), nil
+}
+
+// SetChildBirthdate replaces the child's birthdate; nil clears it.
+func (r *DynamoRepo) SetChildBirthdate(ctx context.Context, childID string, birthdate *string) (*model.Child, error) {
+    it, err := r.getMeta(ctx, "CHILD", childID)
+    if err != nil { return nil, err }
+    if it == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+    in := &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
+        UpdateExpression:    aws.String("REMOVE Birthdate"),
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+        ReturnValues:        types.ReturnValueAllNew,
+    }
+    if birthdate != nil {
+        in.UpdateExpression = aws.String("SET Birthdate = :b")
+        in.ExpressionAttributeValues = map[string]types.AttributeValue{":b": &types.AttributeValueMemberS{Value: *birthdate}}
+    }
+    out, err := r.DB.UpdateItem(ctx, in)
+    if err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "child not found") }
+        return nil, err
+    }
+    var updated item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &updated); err != nil { return nil, err }
+    return toChild(updated), nil
+}
+
+func toChild(it item) *model.Child {
+    return &model.Child{
+        ID: strings.TrimPrefix(it.SK, "CHILD#"), ParentID: it.ParentID, Name: it.Name, Xp: it.XP, Gold: it.Gold,
+        CreatedAt: createdAt(it), Birthdate: it.Birthdate,
+    }
+}
+
+// createdAt is nil for records written before creation times were stored.
+func createdAt(it item) *string {
+    if it.Created == "" { return nil }
+    return &it.Created
+}
+
+// getMeta loads the item registered under GSI2 as tag#id, or nil if none exists.
+func (r *DynamoRepo) getMeta(ctx context.Context, tag, id string) (*item, error) {
+    g2pk, g2sk := gsi2Key(tag, id)
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI2"),
+        KeyConditionExpression: aws.String("GSI2PK = :pk AND GSI2SK = :sk"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: g2pk},
+            ":sk": &types.AttributeValueMemberS{Value: g2sk},
+        },
+        Limit: aws.Int32(1),
+    })
+    if err != nil { return nil, err }
+    if len(out.Items) == 0 { return nil, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Items[0], &it); err != nil { return nil, err }
+    return &it, nil
+}
+
 // Quests
 func (r *DynamoRepo) CreateQuest(ctx context.Context, in model.NewQuest) (*model.Quest, error) {
     qid := uuid.NewString()
-    it := item{PK: pkParent(in.ParentID), SK: skQuest(qid), Type: "Quest", ParentID: in.ParentID, Title: in.Title, Desc: in.Description, XP: in.Xp, Gold: in.Gold}
+    it := item{PK: pkParent(in.ParentID), SK: skQuest(qid), Type: "Quest", ParentID: in.ParentID, Title: in.Title, Desc: in.Description, XP: in.Xp, Gold: in.Gold, Tags: in.Tags, Created: NowRFC3339()}
+    if in.Category != nil { it.Category = string(*in.Category) }
+    if in.Difficulty != nil { it.Difficulty = string(*in.Difficulty) }
     g2pk, g2sk := gsi2Key("QUEST", qid)
     it.GSI2PK, it.GSI2SK = g2pk, g2sk
     av, _ := attributevalue.MarshalMap(it)
     if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
         return nil, err
     }
-    return &model.Quest{ID: qid, ParentID: in.ParentID, Title: in.Title, Description: in.Description, Xp: in.Xp, Gold: in.Gold}, nil
+    return toQuest(it), nil
 }
 
 func (r *DynamoRepo) ListQuests(ctx context.Context, parentID string) ([]*model.Quest, error) {
@@ -121,8 +335,7 @@ func (r *DynamoRepo) ListQuests(ctx context.Context, parentID string) ([]*model.
     for _, m := range out.Items {
         var it item
         if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
-        id := strings.TrimPrefix(it.SK, "QUEST#")
-        res = append(res, &model.Quest{ID: id, ParentID: it.ParentID, Title: it.Title, Description: it.Desc, Xp: it.XP, Gold: it.Gold})
+        res = append(res, toQuest(it))
     }
     return res, nil
 }
@@ -139,11 +352,27 @@ func (r *DynamoRepo) GetQuestByID(ctx context.Context, questID string) (*model.Q
         Limit: aws.Int32(1),
     })
     if err != nil { return nil, err }
-    if len(out.Items) == 0 { return nil, errors.New("quest not found") }
+    if len(out.Items) == 0 { return nil, apperr.New(apperr.NotFound, "quest not found") }
     var it item
     if err := attributevalue.UnmarshalMap(out.Items[0], &it); err != nil { return nil, err }
-    id := strings.TrimPrefix(it.SK, "QUEST#")
-    return &model.Quest{ID: id, ParentID: it.ParentID, Title: it.Title, Description: it.Desc, Xp: it.XP, Gold: it.Gold}, nil
+    return toQuest(it), nil
+}
+
+func toQuest(it item) *model.Quest {
+    q := &model.Quest{
+        ID: strings.TrimPrefix(it.SK, "QUEST#"), ParentID: it.ParentID, Title: it.Title, Description: it.Desc,
+        Xp: it.XP, Gold: it.Gold, CreatedAt: createdAt(it), Tags: it.Tags,
+    }
+    if q.Tags == nil { q.Tags = []string{} }
+    if it.Category != "" {
+        c := model.QuestCategory(it.Category)
+        q.Category = &c
+    }
+    if it.Difficulty != "" {
+        d := model.Difficulty(it.Difficulty)
+        q.Difficulty = &d
+    }
+    return q
 }
 
 // Rewards
@@ -177,6 +406,33 @@ func (r *DynamoRepo) ListRewards(ctx context.Context, parentID string) ([]*model
     return res, nil
 }
 
+// RedeemReward records that a child claimed a reward their XP has unlocked.
+// Each reward can be redeemed once per child.
+func (r *DynamoRepo) RedeemReward(ctx context.Context, childID, rewardID string) (*model.Reward, error) {
+    ch, err := r.GetChild(ctx, childID)
+    if err != nil { return nil, err }
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{
+        TableName: aws.String(r.Table),
+        Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: pkParent(ch.ParentID)}, "SK": &types.AttributeValueMemberS{Value: skReward(rewardID)}},
+    })
+    if err != nil { return nil, err }
+    if out.Item == nil { return nil, apperr.New(apperr.NotFound, "reward not found") }
+    var rw item
+    if err := attributevalue.UnmarshalMap(out.Item, &rw); err != nil { return nil, err }
+    if ch.Xp < rw.XPThresh { return nil, apperr.New(apperr.Validation, "reward needs %d XP", rw.XPThresh) }
+    it := item{PK: pkChild(childID), SK: skRedeem(rewardID), Type: "Redemption", ParentID: ch.ParentID, ChildID: childID, Created: NowRFC3339()}
+    av, _ := attributevalue.MarshalMap(it)
+    reward := &model.Reward{ID: rewardID, ParentID: rw.ParentID, Name: rw.Name, XpThreshold: rw.XPThresh}
+    e := events.New(events.RewardRedeemed, ch.ParentID, childID)
+    e.Reward, e.Child = reward, ch
+    err = r.transact(ctx, []types.TransactWriteItem{{Put: &types.Put{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}}}, e)
+    if err != nil {
+        if txConditionFailed(err, 0) { return nil, apperr.New(apperr.AlreadyCompleted, "reward already redeemed") }
+        return nil, err
+    }
+    return reward, nil
+}
+
 // Assignments
 func (r *DynamoRepo) AssignQuest(ctx context.Context, questID, childID string) (*model.Assignment, error) {
     q, err := r.GetQuestByID(ctx, questID)
@@ -184,14 +440,18 @@ func (r *DynamoRepo) AssignQuest(ctx context.Context, questID, childID string) (
     aid := uuid.NewString()
     it := item{
         PK: pkChild(childID), SK: skAssign(aid), Type: "Assignment",
-        ChildID: childID, QuestID: questID, Status: "ASSIGNED", Created: NowRFC3339(),
+        ParentID: q.ParentID, ChildID: childID, QuestID: questID, Status: "ASSIGNED", Created: NowRFC3339(),
         GSI1PK: "QUEST#" + questID, GSI1SK: "ASSIGN#" + aid,
     }
+    it.GSI2PK, it.GSI2SK = gsi2Key("ASSIGN", aid)
     av, _ := attributevalue.MarshalMap(it)
-    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
+    a := &model.Assignment{ID: aid, Quest: q, ChildID: childID, Status: it.Status, CreatedAt: it.Created}
+    e := events.New(events.AssignmentAssigned, q.ParentID, childID)
+    e.Assignment = a
+    if err := r.transact(ctx, []types.TransactWriteItem{{Put: &types.Put{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}}}, e); err != nil {
         return nil, err
     }
-    return &model.Assignment{ID: aid, Quest: q, ChildID: childID, Status: it.Status, CreatedAt: it.Created}, nil
+    return a, nil
 }
 
 func (r *DynamoRepo) ListAssignmentsForChild(ctx context.Context, childID string) ([]*model.Assignment, error) {
@@ -210,58 +470,65 @@ func (r *DynamoRepo) ListAssignmentsForChild(ctx context.Context, childID string
         if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
         q, _ := r.GetQuestByID(ctx, it.QuestID)
         id := strings.TrimPrefix(it.SK, "ASSIGN#")
-        res = append(res, &model.Assignment{ID: id, Quest: q, ChildID: it.ChildID, Status: it.Status, CreatedAt: it.Created, CompletedAt: it.DoneAt})
+        res = append(res, &model.Assignment{ID: id, Quest: q, ChildID: it.ChildID, Status: it.Status, CreatedAt: it.Created, CompletedAt: it.DoneAt, XpAwarded: it.XPAwarded, GoldAwarded: it.GoldAwarded})
     }
     return res, nil
 }
 
-func (r *DynamoRepo) CompleteAssignment(ctx context.Context, assignmentID string) (*model.Assignment, error) {
-    // Lookup assignment via GSI1 by ID
-    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
-        TableName:              aws.String(r.Table),
-        IndexName:              aws.String("GSI1"),
-        KeyConditionExpression: aws.String("GSI1SK = :sk"),
-        ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":sk": &types.AttributeValueMemberS{Value: "ASSIGN#" + assignmentID},
-        },
-        Limit: aws.Int32(1),
-    })
+func (r *DynamoRepo) GetAssignment(ctx context.Context, assignmentID string) (*model.Assignment, error) {
+    it, err := r.getMeta(ctx, "ASSIGN", assignmentID)
     if err != nil { return nil, err }
-    if len(out.Items) == 0 { return nil, errors.New("assignment not found") }
-    var it item
-    if err := attributevalue.UnmarshalMap(out.Items[0], &it); err != nil { return nil, err }
-
-    // Get quest and child item (via GSI2)
+    if it == nil { return nil, apperr.New(apperr.NotFound, "assignment not found") }
     q, err := r.GetQuestByID(ctx, it.QuestID)
     if err != nil { return nil, err }
-    chq, err := r.DB.Query(ctx, &dynamodb.QueryInput{
-        TableName:              aws.String(r.Table),
-        IndexName:              aws.String("GSI2"),
-        KeyConditionExpression: aws.String("GSI2PK = :pk AND GSI2SK = :sk"),
-        ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":pk": &types.AttributeValueMemberS{Value: "CHILD#" + strings.TrimPrefix(it.PK, "CHILD#")},
-            ":sk": &types.AttributeValueMemberS{Value: "META"},
-        },
-        Limit: aws.Int32(1),
-    })
+    return &model.Assignment{ID: assignmentID, Quest: q, ChildID: it.ChildID, Status: it.Status, CreatedAt: it.Created, CompletedAt: it.DoneAt, XpAwarded: it.XPAwarded, GoldAwarded: it.GoldAwarded}, nil
+}
+
+// CompleteAssignment marks the assignment done and pays its XP and gold, scaled
+// by the household's difficulty multiplier, to the child. The child update is conditioned on the balances read beforehand so
+// the recorded events carry exact balances; concurrent changes are retried.
+func (r *DynamoRepo) CompleteAssignment(ctx context.Context, assignmentID string) (*model.Assignment, error) {
+    // Lookup assignment via GSI2 by ID
+    found, err := r.getMeta(ctx, "ASSIGN", assignmentID)
+    if err != nil { return nil, err }
+    if found == nil { return nil, apperr.New(apperr.NotFound, "assignment not found") }
+    it := *found
+    q, err := r.GetQuestByID(ctx, it.QuestID)
     if err != nil { return nil, err }
-    if len(chq.Items) == 0 { return nil, errors.New("child not found") }
-    var ch item
-    if err := attributevalue.UnmarshalMap(chq.Items[0], &ch); err != nil { return nil, err }
+
+    var m *model.DifficultyMultipliers
+    if q.Difficulty != nil {
+        if m, err = r.GetDifficultyMultipliers(ctx, q.ParentID); err != nil { return nil, err }
+    }
+    xp, gold := m.Pay(q)
 
     done := NowRFC3339()
-    // Transaction: mark assignment completed if not already, and add XP/Gold to child
-    _, err = r.DB.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
-        TransactItems: []types.TransactWriteItem{
+    a := &model.Assignment{ID: strings.TrimPrefix(it.SK, "ASSIGN#"), Quest: q, ChildID: it.ChildID, Status: "COMPLETED", CreatedAt: it.Created, CompletedAt: &done, XpAwarded: &xp, GoldAwarded: &gold}
+    for attempt := 1; ; attempt++ {
+        ch, err := r.getMeta(ctx, "CHILD", it.ChildID)
+        if err != nil { return nil, err }
+        if ch == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+        after := &model.Child{ID: it.ChildID, ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP + xp, Gold: ch.Gold + gold, CreatedAt: createdAt(*ch)}
+        e := events.New(events.AssignmentCompleted, q.ParentID, it.ChildID)
+        e.Assignment, e.Child = a, after
+        evs := []events.Event{e}
+        if lvl := model.LevelForXP(after.Xp); lvl > model.LevelForXP(ch.XP) {
+            lu := events.New(events.LevelUp, q.ParentID, it.ChildID)
+            lu.Child, lu.Level = after, lvl
+            evs = append(evs, lu)
+        }
+
+        // Transaction: mark assignment completed if not already, and add XP/Gold to child
+        err = r.transact(ctx, []types.TransactWriteItem{
             { Update: &types.Update{ TableName: aws.String(r.Table),
                 Key: map[string]types.AttributeValue{
                     "PK": &types.AttributeValueMemberS{Value: it.PK},
                     "SK": &types.AttributeValueMemberS{Value: it.SK},
                 },
-                UpdateExpression:          aws.String("SET #S = :s, CompletedAt = :d"),
+                UpdateExpression:          aws.String("SET #S = :s, CompletedAt = :d, XPAwarded = :xp, GoldAwarded = :g"),
                 ConditionExpression:       aws.String("attribute_not_exists(CompletedAt) AND #S <> :s"),
                 ExpressionAttributeNames:  map[string]string{"#S": "Status"},
-                ExpressionAttributeValues: map[string]types.AttributeValue{":s": &types.AttributeValueMemberS{Value: "COMPLETED"}, ":d": &types.AttributeValueMemberS{Value: done}},
+                ExpressionAttributeValues: map[string]types.AttributeValue{":s": &types.AttributeValueMemberS{Value: "COMPLETED"}, ":d": &types.AttributeValueMemberS{Value: done}, ":xp": num(xp), ":g": num(gold)},
             }},
             { Update: &types.Update{ TableName: aws.String(r.Table),
                 Key: map[string]types.AttributeValue{
@@ -269,48 +536,87 @@ func (r *DynamoRepo) CompleteAssignment(ctx context.Context, assignmentID string
                     "SK": &types.AttributeValueMemberS{Value: ch.SK},
                 },
                 UpdateExpression:          aws.String("ADD XP :xp, Gold :g"),
-                ExpressionAttributeValues: map[string]types.AttributeValue{":xp": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", q.Xp)}, ":g": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", q.Gold)}},
+                ConditionExpression:       aws.String(unchanged("XP", ":xp0", ch.XP) + " AND " + unchanged("Gold", ":g0", ch.Gold)),
+                ExpressionAttributeValues: map[string]types.AttributeValue{":xp": num(xp), ":g": num(gold), ":xp0": num(ch.XP), ":g0": num(ch.Gold)},
             }},
-        },
-    })
-    if err != nil { return nil, err }
-
-    id := strings.TrimPrefix(it.SK, "ASSIGN#")
-    return &model.Assignment{ID: id, Quest: q, ChildID: it.ChildID, Status: "COMPLETED", CreatedAt: it.Created, CompletedAt: &done}, nil
+        }, evs...)
+        switch {
+        case err == nil:
+            return a, nil
+        case txConditionFailed(err, 0):
+            return nil, apperr.New(apperr.AlreadyCompleted, "assignment already completed")
+        case txConditionFailed(err, 1) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 1):
+            return nil, apperr.New(apperr.Conflict, "balance changed concurrently, try again")
+        }
+        return nil, err
+    }
 }
 
+// PurchaseItem spends gold from the child's balance. Like CompleteAssignment
+// it writes only if the balance is unchanged since it was read, so it can
+// never go negative and the event carries the exact new balance.
 func (r *DynamoRepo) PurchaseItem(ctx context.Context, childID, itemName string, priceGold int) (*model.Child, error) {
-    // Load child via GSI2
-    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
-        TableName:              aws.String(r.Table),
-        IndexName:              aws.String("GSI2"),
-        KeyConditionExpression: aws.String("GSI2PK = :pk AND GSI2SK = :sk"),
-        ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":pk": &types.AttributeValueMemberS{Value: "CHILD#" + childID},
-            ":sk": &types.AttributeValueMemberS{Value: "META"},
-        },
-        Limit: aws.Int32(1),
+    for attempt := 1; ; attempt++ {
+        it, err := r.getMeta(ctx, "CHILD", childID)
+        if err != nil { return nil, err }
+        if it == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+        if it.Gold < priceGold { return nil, apperr.New(apperr.InsufficientFunds, "not enough gold") }
+
+        after := &model.Child{ID: childID, ParentID: it.ParentID, Name: it.Name, Xp: it.XP, Gold: it.Gold - priceGold}
+        e := events.New(events.ItemPurchased, it.ParentID, childID)
+        e.Child = after
+        e.Item = &model.AvatarItem{ID: uuid.NewString(), Name: itemName, PriceGold: priceGold}
+        rec := purchaseRecord(it.ParentID, childID, e.Item, e.At)
+        err = r.transact(ctx, []types.TransactWriteItem{{Update: &types.Update{
+            TableName:                 aws.String(r.Table),
+            Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
+            UpdateExpression:          aws.String("ADD Gold :delta"),
+            ConditionExpression:       aws.String(unchanged("Gold", ":g0", it.Gold)),
+            ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(-priceGold), ":g0": num(it.Gold)},
+        }}, {Put: &types.Put{TableName: aws.String(r.Table), Item: rec}}}, e)
+        switch {
+        case err == nil:
+            return after, nil
+        case txConditionFailed(err, 0) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 0):
+            return nil, apperr.New(apperr.Conflict, "balance changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// purchaseRecord is the spending history entry for item bought at at; reports
+// and spending limits read it.
+func purchaseRecord(parentID, childID string, bought *model.AvatarItem, at string) map[string]types.AttributeValue {
+    rec, _ := attributevalue.MarshalMap(item{
+        PK: pkChild(childID), SK: skPurchase(at, bought.ID), Type: "Purchase",
+        ParentID: parentID, ChildID: childID, Name: bought.Name, Gold: bought.PriceGold, Created: at,
     })
-    if err != nil { return nil, err }
-    if len(out.Items) == 0 { return nil, errors.New("child not found") }
-    var it item
-    if err := attributevalue.UnmarshalMap(out.Items[0], &it); err != nil { return nil, err }
+    return rec
+}
 
-    // Attempt spend
-    // We don't enforce non-negative here; add ConditionExpression if desired.
-    _, err = r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
-        TableName: aws.String(r.Table),
-        Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
-        UpdateExpression: aws.String("ADD Gold :delta"),
-        ConditionExpression: aws.String("Gold >= :cost"),
+// ListPurchases returns the child's purchases made in [from, to), oldest first;
+// both bounds are RFC3339 timestamps.
+func (r *DynamoRepo) ListPurchases(ctx context.Context, childID, from, to string) ([]*model.Purchase, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND SK BETWEEN :from AND :to"),
         ExpressionAttributeValues: map[string]types.AttributeValue{
-            ":delta": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", -priceGold)},
-            ":cost":  &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", priceGold)},
+            ":pk":   &types.AttributeValueMemberS{Value: pkChild(childID)},
+            ":from": &types.AttributeValueMemberS{Value: "PURCHASE#" + from},
+            ":to":   &types.AttributeValueMemberS{Value: "PURCHASE#" + to},
         },
-        ReturnValues: types.ReturnValueAllNew,
     })
     if err != nil { return nil, err }
-
-    // Return updated projection (best effort minimal fields)
-    return &model.Child{ID: childID, ParentID: it.ParentID, Name: it.Name, Xp: it.XP, Gold: it.Gold - priceGold}, nil
+    res := make([]*model.Purchase, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        id := it.SK[strings.LastIndex(it.SK, "#")+1:]
+        res = append(res, &model.Purchase{ID: id, ChildID: it.ChildID, ItemName: it.Name, PriceGold: it.Gold, PurchasedAt: it.Created})
+    }
+    return res, nil
 }
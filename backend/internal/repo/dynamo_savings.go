@@ -0,0 +1,260 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/events"
+)
+
+// Savings goals live under the child and are found by ID through GSI2. Goals
+// with a bonus rule are also indexed in GSI1 for the weekly scheduler.
+// MatchDeposits counts deposits since the last bonus while a MATCH rule is set.
+const gsi1SavingsRules = "SAVINGS#RULES"
+
+func skGoal(goalID string) string { return "GOAL#" + goalID }
+
+func (r *DynamoRepo) CreateSavingsGoal(ctx context.Context, parentID, childID, name string, target int) (*model.SavingsGoal, error) {
+    gid := uuid.NewString()
+    it := item{
+        PK: pkChild(childID), SK: skGoal(gid), Type: "SavingsGoal",
+        ParentID: parentID, ChildID: childID, Name: name, Target: target, Created: NowRFC3339(),
+    }
+    it.GSI2PK, it.GSI2SK = gsi2Key("GOAL", gid)
+    av, _ := attributevalue.MarshalMap(it)
+    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
+        return nil, err
+    }
+    return toSavingsGoal(it), nil
+}
+
+func (r *DynamoRepo) ListSavingsGoals(ctx context.Context, childID string) ([]*model.SavingsGoal, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkChild(childID)},
+            ":sk": &types.AttributeValueMemberS{Value: "GOAL#"},
+        },
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.SavingsGoal, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toSavingsGoal(it))
+    }
+    return res, nil
+}
+
+func (r *DynamoRepo) GetSavingsGoal(ctx context.Context, goalID string) (*model.SavingsGoal, error) {
+    it, err := r.getMeta(ctx, "GOAL", goalID)
+    if err != nil { return nil, err }
+    if it == nil { return nil, apperr.New(apperr.NotFound, "savings goal not found") }
+    return toSavingsGoal(*it), nil
+}
+
+// MoveSavings deposits gold (positive) from the child's balance into the goal
+// or withdraws it (negative). Both balances are guarded like PurchaseItem, so
+// neither can go negative and concurrent changes are retried.
+func (r *DynamoRepo) MoveSavings(ctx context.Context, goalID string, gold int) (*model.SavingsGoal, error) {
+    for attempt := 1; ; attempt++ {
+        g, err := r.getMeta(ctx, "GOAL", goalID)
+        if err != nil { return nil, err }
+        if g == nil { return nil, apperr.New(apperr.NotFound, "savings goal not found") }
+        ch, err := r.getMeta(ctx, "CHILD", g.ChildID)
+        if err != nil { return nil, err }
+        if ch == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+        switch {
+        case gold > 0 && g.ReachedAt != nil:
+            return nil, apperr.New(apperr.Validation, "goal already reached")
+        case gold > 0 && g.Saved+gold > g.Target:
+            return nil, apperr.Invalid("gold", "only %d gold left to reach the goal", g.Target-g.Saved)
+        case gold > 0 && ch.Gold < gold:
+            return nil, apperr.New(apperr.InsufficientFunds, "not enough gold")
+        case gold < 0 && g.ReachedAt == nil && !g.EarlyWithdraw:
+            return nil, apperr.New(apperr.Validation, "savings are locked until the goal is reached")
+        case gold < 0 && g.Saved < -gold:
+            return nil, apperr.New(apperr.InsufficientFunds, "not enough gold saved")
+        }
+
+        goal := toSavingsGoal(*g)
+        goal.SavedGold += gold
+        deposits := g.MatchDeposits
+        if g.RuleKind == string(model.SavingsRuleKindMatch) { deposits = max(0, deposits+gold) }
+        set := []string{"MatchDeposits = :m"}
+        vals := map[string]types.AttributeValue{":d": num(gold), ":s0": num(g.Saved), ":m0": num(g.MatchDeposits), ":m": num(deposits)}
+        if goal.ReachedAt == nil && goal.SavedGold >= goal.TargetGold {
+            now := NowRFC3339()
+            set = append(set, "ReachedAt = :now")
+            vals[":now"] = &types.AttributeValueMemberS{Value: now}
+            goal.ReachedAt = &now
+        }
+        after := &model.Child{ID: g.ChildID, ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP, Gold: ch.Gold - gold, CreatedAt: createdAt(*ch)}
+        typ := events.SavingsDeposited
+        if gold < 0 { typ = events.SavingsWithdrawn }
+        e := events.New(typ, g.ParentID, g.ChildID)
+        e.Goal, e.Child = goal, after
+        err = r.transact(ctx, []types.TransactWriteItem{
+            {Update: &types.Update{
+                TableName:                 aws.String(r.Table),
+                Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: g.PK}, "SK": &types.AttributeValueMemberS{Value: g.SK}},
+                UpdateExpression:          aws.String("ADD SavedGold :d SET " + strings.Join(set, ", ")),
+                ConditionExpression:       aws.String(unchanged("SavedGold", ":s0", g.Saved) + " AND " + unchanged("MatchDeposits", ":m0", g.MatchDeposits)),
+                ExpressionAttributeValues: vals,
+            }},
+            {Update: &types.Update{
+                TableName:                 aws.String(r.Table),
+                Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: ch.PK}, "SK": &types.AttributeValueMemberS{Value: ch.SK}},
+                UpdateExpression:          aws.String("ADD Gold :delta"),
+                ConditionExpression:       aws.String(unchanged("Gold", ":g0", ch.Gold)),
+                ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(-gold), ":g0": num(ch.Gold)},
+            }},
+        }, e)
+        switch {
+        case err == nil:
+            return goal, nil
+        case (txConditionFailed(err, 0) || txConditionFailed(err, 1)) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 0) || txConditionFailed(err, 1):
+            return nil, apperr.New(apperr.Conflict, "balance changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// UpdateSavingsGoal replaces the parent's settings. Removing a MATCH rule
+// forgets the deposits counted for it.
+func (r *DynamoRepo) UpdateSavingsGoal(ctx context.Context, goalID string, allowEarly bool, rule *model.SavingsRule, week string) (*model.SavingsGoal, error) {
+    g, err := r.getMeta(ctx, "GOAL", goalID)
+    if err != nil { return nil, err }
+    if g == nil { return nil, apperr.New(apperr.NotFound, "savings goal not found") }
+    set := []string{"AllowEarlyWithdrawal = :early"}
+    remove := []string{"RuleMaxGold"}
+    vals := map[string]types.AttributeValue{":early": &types.AttributeValueMemberBOOL{Value: allowEarly}}
+    if rule != nil {
+        set = append(set, "RuleKind = :k", "RulePercent = :p", "GSI1PK = :g1pk", "GSI1SK = :g1sk")
+        vals[":k"] = &types.AttributeValueMemberS{Value: string(rule.Kind)}
+        vals[":p"] = num(rule.Percent)
+        vals[":g1pk"] = &types.AttributeValueMemberS{Value: gsi1SavingsRules}
+        vals[":g1sk"] = &types.AttributeValueMemberS{Value: goalID}
+        // Mark the current week as settled so the scheduler does not pay for
+        // weeks that ended before the rule existed.
+        if g.RuleKind == "" {
+            set = append(set, "BonusWeek = :week")
+            vals[":week"] = &types.AttributeValueMemberS{Value: week}
+        }
+        if rule.MaxGoldPerWeek != nil {
+            set = append(set, "RuleMaxGold = :cap")
+            vals[":cap"] = num(*rule.MaxGoldPerWeek)
+            remove = nil
+        }
+    } else {
+        remove = append(remove, "RuleKind", "RulePercent", "GSI1PK", "GSI1SK")
+    }
+    if rule == nil || rule.Kind != model.SavingsRuleKindMatch {
+        remove = append(remove, "MatchDeposits")
+    }
+    expr := "SET " + strings.Join(set, ", ")
+    if len(remove) > 0 { expr += " REMOVE " + strings.Join(remove, ", ") }
+    out, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: g.PK}, "SK": &types.AttributeValueMemberS{Value: g.SK}},
+        UpdateExpression:          aws.String(expr),
+        ConditionExpression:       aws.String("attribute_exists(PK)"),
+        ExpressionAttributeValues: vals,
+        ReturnValues:              types.ReturnValueAllNew,
+    })
+    if err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "savings goal not found") }
+        return nil, err
+    }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &it); err != nil { return nil, err }
+    return toSavingsGoal(it), nil
+}
+
+// SavingsGoalsWithRules lists every goal that has a bonus rule.
+func (r *DynamoRepo) SavingsGoalsWithRules(ctx context.Context) ([]*SavingsAccrual, error) {
+    pages := dynamodb.NewQueryPaginator(r.DB, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        KeyConditionExpression: aws.String("GSI1PK = :pk"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: gsi1SavingsRules},
+        },
+    })
+    var res []*SavingsAccrual
+    for pages.HasMorePages() {
+        out, err := pages.NextPage(ctx)
+        if err != nil { return nil, err }
+        for _, m := range out.Items {
+            var it item
+            if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+            res = append(res, &SavingsAccrual{Goal: toSavingsGoal(it), Deposits: it.MatchDeposits, Week: it.BonusWeek})
+        }
+    }
+    return res, nil
+}
+
+// ApplySavingsBonus adds bonus to the goal as the bonus for week, unless the
+// goal already got one for that week or changed since a was read.
+func (r *DynamoRepo) ApplySavingsBonus(ctx context.Context, a *SavingsAccrual, week string, bonus int) (bool, error) {
+    g := a.Goal
+    goal := *g
+    goal.SavedGold += bonus
+    goal.BonusGold += bonus
+    set := []string{"BonusWeek = :w"}
+    remove := " REMOVE MatchDeposits"
+    vals := map[string]types.AttributeValue{
+        ":b": num(bonus), ":s0": num(g.SavedGold), ":m0": num(a.Deposits),
+        ":w": &types.AttributeValueMemberS{Value: week},
+    }
+    if goal.SavedGold >= goal.TargetGold {
+        now := NowRFC3339()
+        set = append(set, "ReachedAt = :now")
+        vals[":now"] = &types.AttributeValueMemberS{Value: now}
+        goal.ReachedAt = &now
+    }
+    var evs []events.Event
+    if bonus > 0 {
+        e := events.New(events.SavingsBonus, g.ParentID, g.ChildID)
+        e.Goal = &goal
+        evs = append(evs, e)
+    }
+    pk, sk := pkChild(g.ChildID), skGoal(g.ID)
+    err := r.transact(ctx, []types.TransactWriteItem{{Update: &types.Update{
+        TableName:        aws.String(r.Table),
+        Key:              map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: pk}, "SK": &types.AttributeValueMemberS{Value: sk}},
+        UpdateExpression: aws.String("ADD SavedGold :b, BonusGold :b SET " + strings.Join(set, ", ") + remove),
+        ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(ReachedAt) AND (attribute_not_exists(BonusWeek) OR BonusWeek < :w) AND " +
+            unchanged("SavedGold", ":s0", g.SavedGold) + " AND " + unchanged("MatchDeposits", ":m0", a.Deposits)),
+        ExpressionAttributeValues: vals,
+    }}}, evs...)
+    if err != nil {
+        if txConditionFailed(err, 0) { return false, nil }
+        return false, err
+    }
+    *g = goal
+    return true, nil
+}
+
+func toSavingsGoal(it item) *model.SavingsGoal {
+    g := &model.SavingsGoal{
+        ID: strings.TrimPrefix(it.SK, "GOAL#"), ParentID: it.ParentID, ChildID: it.ChildID, Name: it.Name,
+        TargetGold: it.Target, SavedGold: it.Saved, BonusGold: it.Bonus, ReachedAt: it.ReachedAt,
+        AllowEarlyWithdrawal: it.EarlyWithdraw, CreatedAt: it.Created,
+    }
+    if it.RuleKind != "" {
+        g.Rule = &model.SavingsRule{Kind: model.SavingsRuleKind(it.RuleKind), Percent: it.RulePercent, MaxGoldPerWeek: it.RuleCap}
+    }
+    return g
+}
@@ -0,0 +1,148 @@
+package repo
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// Quest schedules live under the parent and are indexed in GSI1 by next run
+// time, like pending webhook deliveries, so schedulers can poll for due runs.
+const gsi1ScheduleDue = "SCHEDULE#DUE"
+
+func skSchedule(scheduleID string) string { return "SCHEDULE#" + scheduleID }
+
+func scheduleKey(s *model.QuestSchedule) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(s.ParentID)},
+        "SK": &types.AttributeValueMemberS{Value: skSchedule(s.ID)},
+    }
+}
+
+func (r *DynamoRepo) CreateQuestSchedule(ctx context.Context, parentID, questID, childID string, recurrence model.Recurrence, firstRun time.Time) (*model.QuestSchedule, error) {
+    sid := uuid.NewString()
+    next := firstRun.UTC().Format(time.RFC3339)
+    it := item{
+        PK: pkParent(parentID), SK: skSchedule(sid), Type: "QuestSchedule",
+        ParentID: parentID, QuestID: questID, ChildID: childID, Recurrence: string(recurrence), NextRunAt: next, Created: NowRFC3339(),
+        GSI1PK: gsi1ScheduleDue, GSI1SK: next + "#" + sid,
+    }
+    av, _ := attributevalue.MarshalMap(it)
+    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
+        return nil, err
+    }
+    return toQuestSchedule(it), nil
+}
+
+func (r *DynamoRepo) ListQuestSchedules(ctx context.Context, parentID string) ([]*model.QuestSchedule, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+            ":sk": &types.AttributeValueMemberS{Value: "SCHEDULE#"},
+        },
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.QuestSchedule, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toQuestSchedule(it))
+    }
+    return res, nil
+}
+
+func (r *DynamoRepo) DeleteQuestSchedule(ctx context.Context, parentID, scheduleID string) (*model.QuestSchedule, error) {
+    out, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 scheduleKey(&model.QuestSchedule{ID: scheduleID, ParentID: parentID}),
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+        ReturnValues:        types.ReturnValueAllOld,
+    })
+    if err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "schedule not found") }
+        return nil, err
+    }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &it); err != nil { return nil, err }
+    return toQuestSchedule(it), nil
+}
+
+// DueQuestSchedules returns up to limit schedules whose next run is at or before now.
+func (r *DynamoRepo) DueQuestSchedules(ctx context.Context, now time.Time, limit int) ([]*model.QuestSchedule, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK <= :now"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk":  &types.AttributeValueMemberS{Value: gsi1ScheduleDue},
+            ":now": &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339) + "$"},
+        },
+        Limit: aws.Int32(int32(limit)),
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.QuestSchedule, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toQuestSchedule(it))
+    }
+    return res, nil
+}
+
+// AdvanceQuestSchedule moves s to its next run if no other scheduler did so
+// since s was read; only the caller that advanced a run may execute it.
+func (r *DynamoRepo) AdvanceQuestSchedule(ctx context.Context, s *model.QuestSchedule, next time.Time) (bool, error) {
+    at := next.UTC().Format(time.RFC3339)
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 scheduleKey(s),
+        UpdateExpression:    aws.String("SET NextRunAt = :next, GSI1SK = :sk"),
+        ConditionExpression: aws.String("NextRunAt = :old"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":next": &types.AttributeValueMemberS{Value: at},
+            ":sk":   &types.AttributeValueMemberS{Value: at + "#" + s.ID},
+            ":old":  &types.AttributeValueMemberS{Value: s.NextRunAt},
+        },
+    })
+    if err != nil {
+        if conditionFailed(err) { return false, nil }
+        return false, err
+    }
+    s.NextRunAt = at
+    return true, nil
+}
+
+// SetScheduleAssignment records the assignment a run created; a schedule
+// deleted meanwhile stays deleted.
+func (r *DynamoRepo) SetScheduleAssignment(ctx context.Context, s *model.QuestSchedule, assignmentID string) error {
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       scheduleKey(s),
+        UpdateExpression:          aws.String("SET LastAssignmentID = :a"),
+        ConditionExpression:       aws.String("attribute_exists(PK)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{":a": &types.AttributeValueMemberS{Value: assignmentID}},
+    })
+    if err != nil && !conditionFailed(err) { return err }
+    s.LastAssignmentID = &assignmentID
+    return nil
+}
+
+func toQuestSchedule(it item) *model.QuestSchedule {
+    s := &model.QuestSchedule{
+        ID: strings.TrimPrefix(it.SK, "SCHEDULE#"), ParentID: it.ParentID, QuestID: it.QuestID, ChildID: it.ChildID,
+        Recurrence: model.Recurrence(it.Recurrence), NextRunAt: it.NextRunAt, CreatedAt: it.Created,
+    }
+    if it.LastAssignID != "" { s.LastAssignmentID = &it.LastAssignID }
+    return s
+}
@@ -0,0 +1,241 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/events"
+)
+
+// Spending limits live under the child and the approval item list under the
+// parent. Purchase requests live under the child like payouts, oldest first by
+// SK, indexed per household in GSI1 and by ID in GSI2. Their SK sorts after
+// every PURCHASE# record, so ListPurchases never returns them.
+const (
+    skSpendingLimits = "SPENDING_LIMITS"
+    skApprovalItems  = "APPROVAL_ITEMS"
+)
+
+func skPurchaseRequest(at, requestID string) string { return "PURCHASE_REQUEST#" + at + "#" + requestID }
+func gsi1PurchaseRequests(parentID string) string   { return "PURCHASE_REQUESTS#" + parentID }
+
+func spendingLimitsKey(childID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkChild(childID)},
+        "SK": &types.AttributeValueMemberS{Value: skSpendingLimits},
+    }
+}
+
+func approvalItemsKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skApprovalItems},
+    }
+}
+
+func (r *DynamoRepo) GetSpendingLimits(ctx context.Context, childID string) (*model.SpendingLimits, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: spendingLimitsKey(childID)})
+    if err != nil { return nil, err }
+    l := &model.SpendingLimits{ChildID: childID}
+    if out.Item == nil { return l, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    l.DailyGold, l.WeeklyGold = it.DailyGold, it.WeeklyGold
+    return l, nil
+}
+
+// SetSpendingLimits replaces the child's caps; limits without caps are removed.
+func (r *DynamoRepo) SetSpendingLimits(ctx context.Context, parentID string, l *model.SpendingLimits) error {
+    if l.DailyGold == nil && l.WeeklyGold == nil {
+        _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: spendingLimitsKey(l.ChildID)})
+        return err
+    }
+    av, _ := attributevalue.MarshalMap(item{
+        PK: pkChild(l.ChildID), SK: skSpendingLimits, Type: "SpendingLimits",
+        ParentID: parentID, ChildID: l.ChildID, DailyGold: l.DailyGold, WeeklyGold: l.WeeklyGold,
+    })
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av})
+    return err
+}
+
+func (r *DynamoRepo) GetApprovalItems(ctx context.Context, parentID string) ([]string, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: approvalItemsKey(parentID)})
+    if err != nil { return nil, err }
+    if out.Item == nil { return []string{}, nil }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return it.ItemNames, nil
+}
+
+// SetApprovalItems replaces the household's approval list; an empty list removes it.
+func (r *DynamoRepo) SetApprovalItems(ctx context.Context, parentID string, names []string) error {
+    if len(names) == 0 {
+        _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: approvalItemsKey(parentID)})
+        return err
+    }
+    av, _ := attributevalue.MarshalMap(item{PK: pkParent(parentID), SK: skApprovalItems, Type: "ApprovalItems", ParentID: parentID, ItemNames: names})
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av})
+    return err
+}
+
+// HoldPurchase takes priceGold from the child's balance, guarded like
+// PurchaseItem, and records a pending request for the parent to decide.
+// It returns the request and the child's balance after the hold.
+func (r *DynamoRepo) HoldPurchase(ctx context.Context, childID, itemName string, priceGold int, reason model.PurchaseHoldReason) (*model.PurchaseRequest, *model.Child, error) {
+    for attempt := 1; ; attempt++ {
+        ch, err := r.getMeta(ctx, "CHILD", childID)
+        if err != nil { return nil, nil, err }
+        if ch == nil { return nil, nil, apperr.New(apperr.NotFound, "child not found") }
+        if ch.Gold < priceGold { return nil, nil, apperr.New(apperr.InsufficientFunds, "not enough gold") }
+
+        p := &model.PurchaseRequest{
+            ID: uuid.NewString(), ParentID: ch.ParentID, ChildID: childID, ItemName: itemName, PriceGold: priceGold,
+            Reason: reason, Status: model.PurchaseRequestStatusPending, RequestedAt: NowRFC3339(),
+        }
+        rec := item{
+            PK: pkChild(childID), SK: skPurchaseRequest(p.RequestedAt, p.ID), Type: "PurchaseRequest",
+            ParentID: ch.ParentID, ChildID: childID, Name: itemName, Gold: priceGold, HoldReason: string(reason),
+            Status: string(p.Status), Created: p.RequestedAt,
+            GSI1PK: gsi1PurchaseRequests(ch.ParentID), GSI1SK: p.RequestedAt + "#" + p.ID,
+        }
+        rec.GSI2PK, rec.GSI2SK = gsi2Key("PURCHASE_REQUEST", p.ID)
+        av, _ := attributevalue.MarshalMap(rec)
+        after := &model.Child{ID: childID, ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP, Gold: ch.Gold - priceGold, CreatedAt: createdAt(*ch)}
+        e := events.New(events.PurchaseRequested, ch.ParentID, childID)
+        e.PurchaseRequest, e.Child = p, after
+        err = r.transact(ctx, []types.TransactWriteItem{{Update: &types.Update{
+            TableName:                 aws.String(r.Table),
+            Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: ch.PK}, "SK": &types.AttributeValueMemberS{Value: ch.SK}},
+            UpdateExpression:          aws.String("ADD Gold :delta"),
+            ConditionExpression:       aws.String(unchanged("Gold", ":g0", ch.Gold)),
+            ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(-priceGold), ":g0": num(ch.Gold)},
+        }}, {Put: &types.Put{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}}}, e)
+        switch {
+        case err == nil:
+            return p, after, nil
+        case txConditionFailed(err, 0) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 0):
+            return nil, nil, apperr.New(apperr.Conflict, "balance changed concurrently, try again")
+        }
+        return nil, nil, err
+    }
+}
+
+// DecidePurchase settles a pending request of parentID's household. Approval
+// records the purchase with the held gold and emits item.purchased as if the
+// child had bought it; declining refunds the gold, guarded like PurchaseItem.
+func (r *DynamoRepo) DecidePurchase(ctx context.Context, parentID, requestID string, approve bool) (*model.PurchaseRequest, error) {
+    for attempt := 1; ; attempt++ {
+        it, err := r.getMeta(ctx, "PURCHASE_REQUEST", requestID)
+        if err != nil { return nil, err }
+        if it == nil || it.ParentID != parentID { return nil, apperr.New(apperr.NotFound, "purchase request not found") }
+        p := toPurchaseRequest(*it)
+        if p.Status != model.PurchaseRequestStatusPending { return nil, apperr.New(apperr.Conflict, "purchase request is %s", strings.ToLower(string(p.Status))) }
+        ch, err := r.getMeta(ctx, "CHILD", p.ChildID)
+        if err != nil { return nil, err }
+        if ch == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+
+        now := NowRFC3339()
+        p.DecidedAt = &now
+        p.Status = model.PurchaseRequestStatusApproved
+        if !approve { p.Status = model.PurchaseRequestStatusDeclined }
+        items := []types.TransactWriteItem{{Update: &types.Update{
+            TableName:                aws.String(r.Table),
+            Key:                      map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
+            UpdateExpression:         aws.String("SET #S = :to, DecidedAt = :now"),
+            ConditionExpression:      aws.String("#S = :pending"),
+            ExpressionAttributeNames: map[string]string{"#S": "Status"},
+            ExpressionAttributeValues: map[string]types.AttributeValue{
+                ":to":      &types.AttributeValueMemberS{Value: string(p.Status)},
+                ":now":     &types.AttributeValueMemberS{Value: now},
+                ":pending": &types.AttributeValueMemberS{Value: string(model.PurchaseRequestStatusPending)},
+            },
+        }}}
+        child := &model.Child{ID: p.ChildID, ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP, Gold: ch.Gold, CreatedAt: createdAt(*ch)}
+        var e events.Event
+        if approve {
+            e = events.New(events.ItemPurchased, parentID, p.ChildID)
+            e.Item = &model.AvatarItem{ID: p.ID, Name: p.ItemName, PriceGold: p.PriceGold}
+            items = append(items, types.TransactWriteItem{Put: &types.Put{TableName: aws.String(r.Table), Item: purchaseRecord(parentID, p.ChildID, e.Item, now)}})
+        } else {
+            e = events.New(events.PurchaseDeclined, parentID, p.ChildID)
+            child.Gold += p.PriceGold
+            items = append(items, types.TransactWriteItem{Update: &types.Update{
+                TableName:                 aws.String(r.Table),
+                Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: ch.PK}, "SK": &types.AttributeValueMemberS{Value: ch.SK}},
+                UpdateExpression:          aws.String("ADD Gold :delta"),
+                ConditionExpression:       aws.String(unchanged("Gold", ":g0", ch.Gold)),
+                ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(p.PriceGold), ":g0": num(ch.Gold)},
+            }})
+        }
+        e.PurchaseRequest, e.Child = p, child
+        err = r.transact(ctx, items, e)
+        switch {
+        case err == nil:
+            return p, nil
+        case (txConditionFailed(err, 0) || txConditionFailed(err, 1)) && attempt < balanceRetries:
+            // Re-read: a concurrent decision now reports CONFLICT above.
+            continue
+        case txConditionFailed(err, 0) || txConditionFailed(err, 1):
+            return nil, apperr.New(apperr.Conflict, "purchase request changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// ListPurchaseRequests returns the household's purchase requests, newest first.
+func (r *DynamoRepo) ListPurchaseRequests(ctx context.Context, parentID string) ([]*model.PurchaseRequest, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        KeyConditionExpression: aws.String("GSI1PK = :pk"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: gsi1PurchaseRequests(parentID)},
+        },
+        ScanIndexForward: aws.Bool(false),
+    })
+    if err != nil { return nil, err }
+    return toPurchaseRequests(out.Items)
+}
+
+// ListChildPurchaseRequests returns the child's purchase requests, newest first.
+func (r *DynamoRepo) ListChildPurchaseRequests(ctx context.Context, childID string) ([]*model.PurchaseRequest, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkChild(childID)},
+            ":sk": &types.AttributeValueMemberS{Value: "PURCHASE_REQUEST#"},
+        },
+        ScanIndexForward: aws.Bool(false),
+    })
+    if err != nil { return nil, err }
+    return toPurchaseRequests(out.Items)
+}
+
+func toPurchaseRequests(items []map[string]types.AttributeValue) ([]*model.PurchaseRequest, error) {
+    res := make([]*model.PurchaseRequest, 0, len(items))
+    for _, m := range items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toPurchaseRequest(it))
+    }
+    return res, nil
+}
+
+func toPurchaseRequest(it item) *model.PurchaseRequest {
+    return &model.PurchaseRequest{
+        ID: it.SK[strings.LastIndex(it.SK, "#")+1:], ParentID: it.ParentID, ChildID: it.ChildID, ItemName: it.Name,
+        PriceGold: it.Gold, Reason: model.PurchaseHoldReason(it.HoldReason), Status: model.PurchaseRequestStatus(it.Status),
+        RequestedAt: it.Created, DecidedAt: it.DecidedAt,
+    }
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+    "context"
+    "encoding/json"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// A parent's quest templates live under the parent. Shared template sets are
+// snapshots under their export code and expire through the table's TTL.
+func skTemplate(templateID string) string { return "TEMPLATE#" + templateID }
+func pkShare(code string) string          { return "SHARE#" + code }
+
+func (r *DynamoRepo) CreateQuestTemplate(ctx context.Context, t *model.QuestTemplate) (*model.QuestTemplate, error) {
+    if t.ParentID == nil { return nil, apperr.Invalid("parentId", "required") }
+    out := *t
+    out.ID = uuid.NewString()
+    it := item{
+        PK: pkParent(*t.ParentID), SK: skTemplate(out.ID), Type: "QuestTemplate",
+        ParentID: *t.ParentID, Title: t.Title, Desc: t.Description, XP: t.Xp, Gold: t.Gold, MinAge: t.MinAge, MaxAge: t.MaxAge, Created: NowRFC3339(),
+    }
+    if t.Category != nil { it.Category = string(*t.Category) }
+    av, _ := attributevalue.MarshalMap(it)
+    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// ListQuestTemplates returns the parent's own templates, oldest first.
+func (r *DynamoRepo) ListQuestTemplates(ctx context.Context, parentID string) ([]*model.QuestTemplate, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+            ":sk": &types.AttributeValueMemberS{Value: "TEMPLATE#"},
+        },
+    })
+    if err != nil { return nil, err }
+    its := make([]item, len(out.Items))
+    for i, m := range out.Items {
+        if err := attributevalue.UnmarshalMap(m, &its[i]); err != nil { return nil, err }
+    }
+    sort.SliceStable(its, func(i, j int) bool { return its[i].Created < its[j].Created })
+    res := make([]*model.QuestTemplate, 0, len(its))
+    for _, it := range its {
+        res = append(res, toQuestTemplate(it))
+    }
+    return res, nil
+}
+
+func (r *DynamoRepo) DeleteQuestTemplate(ctx context.Context, parentID, templateID string) (*model.QuestTemplate, error) {
+    out, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+        TableName: aws.String(r.Table),
+        Key: map[string]types.AttributeValue{
+            "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+            "SK": &types.AttributeValueMemberS{Value: skTemplate(templateID)},
+        },
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+        ReturnValues:        types.ReturnValueAllOld,
+    })
+    if err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "template not found") }
+        return nil, err
+    }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &it); err != nil { return nil, err }
+    return toQuestTemplate(it), nil
+}
+
+// ShareQuestTemplates stores a copy of ts under code until expires. It fails
+// with CONFLICT if the code is taken.
+func (r *DynamoRepo) ShareQuestTemplates(ctx context.Context, parentID, code string, ts []*model.QuestTemplate, expires time.Time) error {
+    payload, err := json.Marshal(ts)
+    if err != nil { return err }
+    av, _ := attributevalue.MarshalMap(item{
+        PK: pkShare(code), SK: "SHARE", Type: "TemplateShare", ParentID: parentID,
+        Payload: string(payload), Created: NowRFC3339(), ExpiresAt: expires.Unix(),
+    })
+    _, err = r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")})
+    if conditionFailed(err) { return apperr.New(apperr.Conflict, "export code already in use, try again") }
+    return err
+}
+
+// SharedQuestTemplates returns the templates shared under code, or NOT_FOUND
+// once the code expired.
+func (r *DynamoRepo) SharedQuestTemplates(ctx context.Context, code string) ([]*model.QuestTemplate, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{
+        TableName: aws.String(r.Table),
+        Key: map[string]types.AttributeValue{
+            "PK": &types.AttributeValueMemberS{Value: pkShare(code)},
+            "SK": &types.AttributeValueMemberS{Value: "SHARE"},
+        },
+    })
+    if err != nil { return nil, err }
+    var it item
+    if out.Item != nil {
+        if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    }
+    // TTL deletion is lazy, so an expired share may still be present.
+    if out.Item == nil || it.ExpiresAt < time.Now().Unix() { return nil, apperr.New(apperr.NotFound, "export code not found or expired") }
+    var ts []*model.QuestTemplate
+    if err := json.Unmarshal([]byte(it.Payload), &ts); err != nil { return nil, err }
+    return ts, nil
+}
+
+func toQuestTemplate(it item) *model.QuestTemplate {
+    parentID := it.ParentID
+    t := &model.QuestTemplate{
+        ID: strings.TrimPrefix(it.SK, "TEMPLATE#"), ParentID: &parentID, Title: it.Title, Description: it.Desc,
+        Xp: it.XP, Gold: it.Gold, MinAge: it.MinAge, MaxAge: it.MaxAge,
+    }
+    if it.Category != "" {
+        c := model.QuestCategory(it.Category)
+        t.Category = &c
+    }
+    return t
+}
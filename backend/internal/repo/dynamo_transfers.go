@@ -0,0 +1,188 @@
+package repo
+
+import (
+    "context"
+    "strings"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/events"
+)
+
+// Transfers live under the parent, oldest first by SK, and are found by ID
+// through GSI2. The approval threshold is a separate item under the parent.
+const skTransferSettings = "TRANSFERS"
+
+func skTransfer(at, transferID string) string { return "TRANSFER#" + at + "#" + transferID }
+
+func transferSettingsKey(parentID string) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+        "SK": &types.AttributeValueMemberS{Value: skTransferSettings},
+    }
+}
+
+// addGold changes the child's gold only if it still holds the balance read into ch.
+func (r *DynamoRepo) addGold(ch *item, delta int) types.TransactWriteItem {
+    return types.TransactWriteItem{Update: &types.Update{
+        TableName:                 aws.String(r.Table),
+        Key:                       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: ch.PK}, "SK": &types.AttributeValueMemberS{Value: ch.SK}},
+        UpdateExpression:          aws.String("ADD Gold :delta"),
+        ConditionExpression:       aws.String(unchanged("Gold", ":g0", ch.Gold)),
+        ExpressionAttributeValues: map[string]types.AttributeValue{":delta": num(delta), ":g0": num(ch.Gold)},
+    }}
+}
+
+func childAfter(ch *item, delta int) *model.Child {
+    return &model.Child{ID: strings.TrimPrefix(ch.SK, "CHILD#"), ParentID: ch.ParentID, Name: ch.Name, Xp: ch.XP, Gold: ch.Gold + delta, CreatedAt: createdAt(*ch)}
+}
+
+func (r *DynamoRepo) GetTransferThreshold(ctx context.Context, parentID string) (*int, error) {
+    out, err := r.DB.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.Table), Key: transferSettingsKey(parentID)})
+    if err != nil || out.Item == nil { return nil, err }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Item, &it); err != nil { return nil, err }
+    return it.Threshold, nil
+}
+
+// SetTransferThreshold replaces the approval threshold; nil removes it.
+func (r *DynamoRepo) SetTransferThreshold(ctx context.Context, parentID string, gold *int) error {
+    if gold == nil {
+        _, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(r.Table), Key: transferSettingsKey(parentID)})
+        return err
+    }
+    av, _ := attributevalue.MarshalMap(item{PK: pkParent(parentID), SK: skTransferSettings, Type: "TransferSettings", ParentID: parentID, Threshold: gold})
+    _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av})
+    return err
+}
+
+// TransferGold moves gold between two children of one household in a single
+// transaction, guarding both balances like PurchaseItem. A pending transfer
+// only takes the sender's gold; DecideTransfer delivers or refunds it.
+func (r *DynamoRepo) TransferGold(ctx context.Context, fromChildID, toChildID string, gold int, note *string, pending bool) (*model.GoldTransfer, error) {
+    for attempt := 1; ; attempt++ {
+        from, err := r.getMeta(ctx, "CHILD", fromChildID)
+        if err != nil { return nil, err }
+        to, err := r.getMeta(ctx, "CHILD", toChildID)
+        if err != nil { return nil, err }
+        if from == nil || to == nil || from.ParentID != to.ParentID { return nil, apperr.New(apperr.NotFound, "child not found") }
+        if from.Gold < gold { return nil, apperr.New(apperr.InsufficientFunds, "not enough gold") }
+
+        t := &model.GoldTransfer{
+            ID: uuid.NewString(), ParentID: from.ParentID, FromChildID: fromChildID, ToChildID: toChildID,
+            Gold: gold, Note: note, Status: model.TransferStatusCompleted, CreatedAt: NowRFC3339(),
+        }
+        if pending { t.Status = model.TransferStatusPending }
+        rec := item{
+            PK: pkParent(t.ParentID), SK: skTransfer(t.CreatedAt, t.ID), Type: "GoldTransfer",
+            ParentID: t.ParentID, ChildID: fromChildID, ToChildID: toChildID, Gold: gold, Note: note,
+            Status: string(t.Status), Created: t.CreatedAt,
+        }
+        rec.GSI2PK, rec.GSI2SK = gsi2Key("TRANSFER", t.ID)
+        av, _ := attributevalue.MarshalMap(rec)
+        items := []types.TransactWriteItem{r.addGold(from, -gold), {Put: &types.Put{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}}}
+        sent := events.New(events.GoldSent, t.ParentID, fromChildID)
+        sent.Transfer, sent.Child = t, childAfter(from, -gold)
+        evs := []events.Event{sent}
+        if !pending {
+            items = append(items, r.addGold(to, gold))
+            got := events.New(events.GoldReceived, t.ParentID, toChildID)
+            got.Transfer, got.Child = t, childAfter(to, gold)
+            evs = append(evs, got)
+        }
+        err = r.transact(ctx, items, evs...)
+        switch {
+        case err == nil:
+            return t, nil
+        case (txConditionFailed(err, 0) || txConditionFailed(err, 2)) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 0) || txConditionFailed(err, 2):
+            return nil, apperr.New(apperr.Conflict, "balance changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// DecideTransfer completes a pending transfer of parentID's household by
+// crediting the recipient, or declines it by refunding the sender.
+func (r *DynamoRepo) DecideTransfer(ctx context.Context, parentID, transferID string, approve bool) (*model.GoldTransfer, error) {
+    for attempt := 1; ; attempt++ {
+        it, err := r.getMeta(ctx, "TRANSFER", transferID)
+        if err != nil { return nil, err }
+        if it == nil || it.ParentID != parentID { return nil, apperr.New(apperr.NotFound, "transfer not found") }
+        t := toTransfer(*it)
+        if t.Status != model.TransferStatusPending { return nil, apperr.New(apperr.Conflict, "transfer is %s", strings.ToLower(string(t.Status))) }
+
+        childID, typ := t.ToChildID, events.GoldReceived
+        t.Status = model.TransferStatusCompleted
+        if !approve {
+            childID, typ = t.FromChildID, events.TransferDeclined
+            t.Status = model.TransferStatusDeclined
+        }
+        ch, err := r.getMeta(ctx, "CHILD", childID)
+        if err != nil { return nil, err }
+        if ch == nil { return nil, apperr.New(apperr.NotFound, "child not found") }
+        now := NowRFC3339()
+        t.DecidedAt = &now
+        e := events.New(typ, parentID, childID)
+        e.Transfer, e.Child = t, childAfter(ch, t.Gold)
+        err = r.transact(ctx, []types.TransactWriteItem{
+            {Update: &types.Update{
+                TableName:                aws.String(r.Table),
+                Key:                      map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: it.PK}, "SK": &types.AttributeValueMemberS{Value: it.SK}},
+                UpdateExpression:         aws.String("SET #S = :to, DecidedAt = :now"),
+                ConditionExpression:      aws.String("#S = :pending"),
+                ExpressionAttributeNames: map[string]string{"#S": "Status"},
+                ExpressionAttributeValues: map[string]types.AttributeValue{
+                    ":to":      &types.AttributeValueMemberS{Value: string(t.Status)},
+                    ":now":     &types.AttributeValueMemberS{Value: now},
+                    ":pending": &types.AttributeValueMemberS{Value: string(model.TransferStatusPending)},
+                },
+            }},
+            r.addGold(ch, t.Gold),
+        }, e)
+        switch {
+        case err == nil:
+            return t, nil
+        case (txConditionFailed(err, 0) || txConditionFailed(err, 1)) && attempt < balanceRetries:
+            continue
+        case txConditionFailed(err, 0) || txConditionFailed(err, 1):
+            return nil, apperr.New(apperr.Conflict, "transfer changed concurrently, try again")
+        }
+        return nil, err
+    }
+}
+
+// ListTransfers returns the household's transfers, newest first.
+func (r *DynamoRepo) ListTransfers(ctx context.Context, parentID string) ([]*model.GoldTransfer, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+            ":sk": &types.AttributeValueMemberS{Value: "TRANSFER#"},
+        },
+        ScanIndexForward: aws.Bool(false),
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.GoldTransfer, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toTransfer(it))
+    }
+    return res, nil
+}
+
+func toTransfer(it item) *model.GoldTransfer {
+    return &model.GoldTransfer{
+        ID: it.SK[strings.LastIndex(it.SK, "#")+1:], ParentID: it.ParentID, FromChildID: it.ChildID, ToChildID: it.ToChildID,
+        Gold: it.Gold, Note: it.Note, Status: model.TransferStatus(it.Status), CreatedAt: it.Created, DecidedAt: it.DecidedAt,
+    }
+}
@@ -0,0 +1,245 @@
+package repo
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/aws/aws-sdk-go-v2/aws"
+    "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+    "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+)
+
+// Deliveries live under their webhook, newest last by SK. Pending ones are also
+// indexed in GSI1 by next attempt time so dispatchers can poll for due work.
+const (
+    gsi1WebhookPending = "WEBHOOK#PENDING"
+    deliveryRetention  = 30 * 24 * time.Hour
+)
+
+func pkWebhook(webhookID string) string { return "WEBHOOK#" + webhookID }
+func skDelivery(createdAt, deliveryID string) string { return "DELIVERY#" + createdAt + "#" + deliveryID }
+
+func deliveryKey(d *model.WebhookDelivery) map[string]types.AttributeValue {
+    return map[string]types.AttributeValue{
+        "PK": &types.AttributeValueMemberS{Value: pkWebhook(d.WebhookID)},
+        "SK": &types.AttributeValueMemberS{Value: skDelivery(d.CreatedAt, d.ID)},
+    }
+}
+
+// Webhooks
+func (r *DynamoRepo) CreateWebhook(ctx context.Context, parentID, url, secret string, events []model.WebhookEvent) (*model.Webhook, error) {
+    wid := uuid.NewString()
+    it := item{PK: pkParent(parentID), SK: skWebhook(wid), Type: "Webhook", ParentID: parentID, URL: url, Secret: secret, Created: NowRFC3339()}
+    for _, e := range events {
+        it.Events = append(it.Events, string(e))
+    }
+    it.GSI2PK, it.GSI2SK = gsi2Key("WEBHOOK", wid)
+    av, _ := attributevalue.MarshalMap(it)
+    if _, err := r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")}); err != nil {
+        return nil, err
+    }
+    return toWebhook(it), nil
+}
+
+func (r *DynamoRepo) ListWebhooks(ctx context.Context, parentID string) ([]*model.Webhook, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkParent(parentID)},
+            ":sk": &types.AttributeValueMemberS{Value: "WEBHOOK#"},
+        },
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.Webhook, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toWebhook(it))
+    }
+    return res, nil
+}
+
+func (r *DynamoRepo) GetWebhook(ctx context.Context, webhookID string) (*model.Webhook, string, error) {
+    it, err := r.getMeta(ctx, "WEBHOOK", webhookID)
+    if err != nil { return nil, "", err }
+    if it == nil { return nil, "", apperr.New(apperr.NotFound, "webhook not found") }
+    return toWebhook(*it), it.Secret, nil
+}
+
+// DeleteWebhook removes the endpoint; its pending deliveries are dead-lettered
+// by the dispatcher when it finds the webhook gone.
+func (r *DynamoRepo) DeleteWebhook(ctx context.Context, parentID, webhookID string) (*model.Webhook, error) {
+    out, err := r.DB.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: pkParent(parentID)}, "SK": &types.AttributeValueMemberS{Value: skWebhook(webhookID)}},
+        ConditionExpression: aws.String("attribute_exists(PK)"),
+        ReturnValues:        types.ReturnValueAllOld,
+    })
+    if err != nil {
+        if conditionFailed(err) { return nil, apperr.New(apperr.NotFound, "webhook not found") }
+        return nil, err
+    }
+    var it item
+    if err := attributevalue.UnmarshalMap(out.Attributes, &it); err != nil { return nil, err }
+    return toWebhook(it), nil
+}
+
+func toWebhook(it item) *model.Webhook {
+    w := &model.Webhook{
+        ID: strings.TrimPrefix(it.SK, "WEBHOOK#"), ParentID: it.ParentID, URL: it.URL,
+        Events: make([]model.WebhookEvent, 0, len(it.Events)), CreatedAt: it.Created,
+    }
+    for _, e := range it.Events {
+        w.Events = append(w.Events, model.WebhookEvent(e))
+    }
+    return w
+}
+
+// Webhook deliveries
+func (r *DynamoRepo) CreateWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, payload []byte) error {
+    created, err := time.Parse(time.RFC3339, d.CreatedAt)
+    if err != nil { return err }
+    it := item{
+        PK: pkWebhook(d.WebhookID), SK: skDelivery(d.CreatedAt, d.ID), Type: "WebhookDelivery",
+        WebhookID: d.WebhookID, EventID: d.EventID, EventType: string(d.Event), Payload: string(payload),
+        Status: string(d.Status), Created: d.CreatedAt, NextAt: d.NextAttemptAt,
+        ExpiresAt: created.Add(deliveryRetention).Unix(),
+    }
+    if d.NextAttemptAt != nil {
+        it.GSI1PK, it.GSI1SK = gsi1WebhookPending, *d.NextAttemptAt+"#"+d.ID
+    }
+    av, _ := attributevalue.MarshalMap(it)
+    _, err = r.DB.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(r.Table), Item: av, ConditionExpression: aws.String("attribute_not_exists(PK)")})
+    if conditionFailed(err) { return apperr.New(apperr.Conflict, "delivery already recorded") }
+    return err
+}
+
+func (r *DynamoRepo) DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookJob, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        IndexName:              aws.String("GSI1"),
+        // "$" sorts after "#", so deliveries due within the current second match.
+        KeyConditionExpression: aws.String("GSI1PK = :pk AND GSI1SK <= :now"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk":  &types.AttributeValueMemberS{Value: gsi1WebhookPending},
+            ":now": &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339) + "$"},
+        },
+        Limit: aws.Int32(int32(limit)),
+    })
+    if err != nil { return nil, err }
+    res := make([]*WebhookJob, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, &WebhookJob{Delivery: toDelivery(it), Payload: []byte(it.Payload)})
+    }
+    return res, nil
+}
+
+// LeaseWebhookDelivery pushes a due delivery's next attempt out to until so
+// other dispatchers skip it while it is in flight. It reports false when
+// another dispatcher leased or finished the delivery first.
+func (r *DynamoRepo) LeaseWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, until time.Time) (bool, error) {
+    if d.NextAttemptAt == nil { return false, nil }
+    next := until.UTC().Format(time.RFC3339)
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:           aws.String(r.Table),
+        Key:                 deliveryKey(d),
+        UpdateExpression:    aws.String("SET NextAttemptAt = :next, GSI1SK = :sk"),
+        ConditionExpression: aws.String("GSI1SK = :old"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":next": &types.AttributeValueMemberS{Value: next},
+            ":sk":   &types.AttributeValueMemberS{Value: next + "#" + d.ID},
+            ":old":  &types.AttributeValueMemberS{Value: *d.NextAttemptAt + "#" + d.ID},
+        },
+    })
+    if err != nil {
+        if conditionFailed(err) { return false, nil }
+        return false, err
+    }
+    d.NextAttemptAt = &next
+    return true, nil
+}
+
+// SaveWebhookDelivery records the outcome of an attempt. Deliveries without a
+// next attempt leave the pending index.
+func (r *DynamoRepo) SaveWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+    set := []string{"#S = :s", "Attempts = :a"}
+    var remove []string
+    vals := map[string]types.AttributeValue{
+        ":s": &types.AttributeValueMemberS{Value: string(d.Status)},
+        ":a": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", d.Attempts)},
+    }
+    optional := func(name, placeholder string, v types.AttributeValue) {
+        if v == nil {
+            remove = append(remove, name)
+            return
+        }
+        set = append(set, name+" = "+placeholder)
+        vals[placeholder] = v
+    }
+    optional("ResponseStatus", ":rs", nilOr(d.ResponseStatus, func(n int) types.AttributeValue { return &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", n)} }))
+    optional("LastError", ":le", nilOr(d.LastError, func(s string) types.AttributeValue { return &types.AttributeValueMemberS{Value: s} }))
+    optional("DeliveredAt", ":da", nilOr(d.DeliveredAt, func(s string) types.AttributeValue { return &types.AttributeValueMemberS{Value: s} }))
+    optional("NextAttemptAt", ":na", nilOr(d.NextAttemptAt, func(s string) types.AttributeValue { return &types.AttributeValueMemberS{Value: s} }))
+    if d.NextAttemptAt != nil {
+        set = append(set, "GSI1PK = :gpk", "GSI1SK = :gsk")
+        vals[":gpk"] = &types.AttributeValueMemberS{Value: gsi1WebhookPending}
+        vals[":gsk"] = &types.AttributeValueMemberS{Value: *d.NextAttemptAt + "#" + d.ID}
+    } else {
+        remove = append(remove, "GSI1PK", "GSI1SK")
+    }
+    expr := "SET " + strings.Join(set, ", ")
+    if len(remove) > 0 { expr += " REMOVE " + strings.Join(remove, ", ") }
+    _, err := r.DB.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+        TableName:                 aws.String(r.Table),
+        Key:                       deliveryKey(d),
+        UpdateExpression:          aws.String(expr),
+        ConditionExpression:       aws.String("attribute_exists(PK)"),
+        ExpressionAttributeNames:  map[string]string{"#S": "Status"},
+        ExpressionAttributeValues: vals,
+    })
+    return err
+}
+
+func (r *DynamoRepo) ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*model.WebhookDelivery, error) {
+    out, err := r.DB.Query(ctx, &dynamodb.QueryInput{
+        TableName:              aws.String(r.Table),
+        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+        ExpressionAttributeValues: map[string]types.AttributeValue{
+            ":pk": &types.AttributeValueMemberS{Value: pkWebhook(webhookID)},
+            ":sk": &types.AttributeValueMemberS{Value: "DELIVERY#"},
+        },
+        ScanIndexForward: aws.Bool(false),
+        Limit:            aws.Int32(int32(limit)),
+    })
+    if err != nil { return nil, err }
+    res := make([]*model.WebhookDelivery, 0, len(out.Items))
+    for _, m := range out.Items {
+        var it item
+        if err := attributevalue.UnmarshalMap(m, &it); err != nil { return nil, err }
+        res = append(res, toDelivery(it))
+    }
+    return res, nil
+}
+
+func toDelivery(it item) *model.WebhookDelivery {
+    return &model.WebhookDelivery{
+        ID: it.SK[strings.LastIndex(it.SK, "#")+1:], WebhookID: it.WebhookID, EventID: it.EventID, Event: model.WebhookEvent(it.EventType),
+        Status: model.WebhookDeliveryStatus(it.Status), Attempts: it.Attempts, ResponseStatus: it.HTTPStatus, LastError: it.LastError,
+        CreatedAt: it.Created, NextAttemptAt: it.NextAt, DeliveredAt: it.DeliveredAt,
+    }
+}
+
+func nilOr[T any](p *T, f func(T) types.AttributeValue) types.AttributeValue {
+    if p == nil { return nil }
+    return f(*p)
+}
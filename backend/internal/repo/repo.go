@@ -5,12 +5,15 @@ import (
     "time"
 
     "chorequest/backend/graph/model"
+    "chorequest/backend/internal/events"
 )
 
 // Repo defines operations for the domain backed by DynamoDB.
 type Repo interface {
     CreateChild(ctx context.Context, in model.NewChild) (*model.Child, error)
     ListChildren(ctx context.Context, parentID string) ([]*model.Child, error)
+    GetChild(ctx context.Context, childID string) (*model.Child, error)
+    SetChildBirthdate(ctx context.Context, childID string, birthdate *string) (*model.Child, error)
 
     CreateQuest(ctx context.Context, in model.NewQuest) (*model.Quest, error)
     ListQuests(ctx context.Context, parentID string) ([]*model.Quest, error)
@@ -18,12 +21,220 @@ type Repo interface {
 
     AssignQuest(ctx context.Context, questID, childID string) (*model.Assignment, error)
     ListAssignmentsForChild(ctx context.Context, childID string) ([]*model.Assignment, error)
+    GetAssignment(ctx context.Context, assignmentID string) (*model.Assignment, error)
     CompleteAssignment(ctx context.Context, assignmentID string) (*model.Assignment, error)
 
     CreateReward(ctx context.Context, in model.NewReward) (*model.Reward, error)
     ListRewards(ctx context.Context, parentID string) ([]*model.Reward, error)
+    RedeemReward(ctx context.Context, childID, rewardID string) (*model.Reward, error)
 
     PurchaseItem(ctx context.Context, childID, itemName string, priceGold int) (*model.Child, error)
+    ListPurchases(ctx context.Context, childID, from, to string) ([]*model.Purchase, error)
+
+    // API keys: only the hash of a key is stored; lookups go through GSI2.
+    CreateAPIKey(ctx context.Context, parentID, name, prefix, hash string, scopes []model.APIKeyScope) (*model.APIKey, error)
+    ListAPIKeys(ctx context.Context, parentID string) ([]*model.APIKey, error)
+    GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+    TouchAPIKey(ctx context.Context, parentID, keyID string) error
+    RevokeAPIKey(ctx context.Context, parentID, keyID string) (*model.APIKey, error)
+
+    // Parent email/password logins. CreateLogin fails with CONFLICT if the
+    // email is taken; GetLogin returns nil if there is no such login.
+    CreateLogin(ctx context.Context, email, parentID, passwordHash string) error
+    GetLogin(ctx context.Context, email string) (*Login, error)
+
+    // Parent TOTP two-factor state.
+    GetMFA(ctx context.Context, parentID string) (*MFAState, error)
+    SetPendingTOTP(ctx context.Context, parentID, secret string) error
+    EnableMFA(ctx context.Context, parentID string, recoveryHashes []string) error
+    ReplaceRecoveryCodes(ctx context.Context, parentID string, recoveryHashes []string) error
+    UseTOTPStep(ctx context.Context, parentID string, step int64) error
+    UseRecoveryCode(ctx context.Context, parentID, hash string) error
+    DisableMFA(ctx context.Context, parentID string) error
+
+    // Idempotency: ClaimIdempotencyKey reserves key for a new request for
+    // lease, or returns the existing unexpired record with claimed=false; an
+    // expired claim is taken over. The claimed record carries the Claim that
+    // Complete and Release need; they do nothing once the claim was taken over.
+    ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, lease time.Duration) (rec *IdempotencyRecord, claimed bool, err error)
+    CompleteIdempotencyKey(ctx context.Context, key, claim string, response []byte, ttl time.Duration) error
+    ReleaseIdempotencyKey(ctx context.Context, key, claim string) error
+
+    // Webhooks: endpoints keep their signing secret; deliveries form a
+    // per-webhook log, and pending ones are indexed by next attempt time.
+    CreateWebhook(ctx context.Context, parentID, url, secret string, events []model.WebhookEvent) (*model.Webhook, error)
+    ListWebhooks(ctx context.Context, parentID string) ([]*model.Webhook, error)
+    GetWebhook(ctx context.Context, webhookID string) (hook *model.Webhook, secret string, err error)
+    DeleteWebhook(ctx context.Context, parentID, webhookID string) (*model.Webhook, error)
+    CreateWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, payload []byte) error
+    DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookJob, error)
+    LeaseWebhookDelivery(ctx context.Context, d *model.WebhookDelivery, until time.Time) (bool, error)
+    SaveWebhookDelivery(ctx context.Context, d *model.WebhookDelivery) error
+    ListWebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*model.WebhookDelivery, error)
+
+    // Transactional outbox: state-changing methods record their events in the
+    // same transaction; a dispatcher leases, publishes and marks them delivered.
+    PendingOutbox(ctx context.Context, now time.Time, limit int) ([]*OutboxEntry, error)
+    LeaseOutbox(ctx context.Context, o *OutboxEntry, until time.Time) (bool, error)
+    // FailOutbox records a failed attempt on a leased entry and schedules it
+    // for next, or dead-letters it when next is nil.
+    FailOutbox(ctx context.Context, o *OutboxEntry, lastErr string, next *time.Time) error
+    MarkOutboxDelivered(ctx context.Context, eventID string) error
+
+    // Notification preferences per parent; unsaved preferences read as defaults.
+    GetNotificationPreferences(ctx context.Context, parentID string) (*model.NotificationPreferences, error)
+    SaveNotificationPreferences(ctx context.Context, p *model.NotificationPreferences) error
+    DigestRecipients(ctx context.Context) ([]*model.NotificationPreferences, error)
+    ClaimDigest(ctx context.Context, parentID, week string) (bool, error)
+    ReleaseDigest(ctx context.Context, parentID, week string) error
+
+    // Web Push: subscriptions per child device, keyed by endpoint, and the
+    // child's quiet hours (nil when none).
+    SavePushSubscription(ctx context.Context, parentID, childID, endpoint, p256dh, auth string) (*model.PushSubscription, error)
+    ListPushSubscriptions(ctx context.Context, childID string) ([]*PushTarget, error)
+    DeletePushSubscription(ctx context.Context, childID, subID string) (*model.PushSubscription, error)
+    GetQuietHours(ctx context.Context, childID string) (*model.QuietHours, error)
+    SetQuietHours(ctx context.Context, childID string, q *model.QuietHours) error
+
+    // Billing: Stripe customer and subscription per parent, updated from
+    // Stripe webhooks in event order.
+    GetBilling(ctx context.Context, parentID string) (*Billing, error)
+    ParentForCustomer(ctx context.Context, customerID string) (string, error)
+    LinkCustomer(ctx context.Context, parentID, customerID, subscriptionID string) error
+    ApplySubscription(ctx context.Context, parentID string, b Billing, at time.Time) (applied bool, err error)
+    RecordPaymentFailure(ctx context.Context, parentID string, at time.Time) (applied bool, err error)
+
+    // Quest schedules: recurring assignments, indexed by next run time.
+    CreateQuestSchedule(ctx context.Context, parentID, questID, childID string, recurrence model.Recurrence, firstRun time.Time) (*model.QuestSchedule, error)
+    ListQuestSchedules(ctx context.Context, parentID string) ([]*model.QuestSchedule, error)
+    DeleteQuestSchedule(ctx context.Context, parentID, scheduleID string) (*model.QuestSchedule, error)
+    DueQuestSchedules(ctx context.Context, now time.Time, limit int) ([]*model.QuestSchedule, error)
+    AdvanceQuestSchedule(ctx context.Context, s *model.QuestSchedule, next time.Time) (bool, error)
+    SetScheduleAssignment(ctx context.Context, s *model.QuestSchedule, assignmentID string) error
+
+    // Allowance payouts: the household's gold exchange rate (nil when unset)
+    // and cash-out requests that hold the child's gold until decided.
+    GetExchangeRate(ctx context.Context, parentID string) (*model.ExchangeRate, error)
+    SetExchangeRate(ctx context.Context, parentID string, rate *model.ExchangeRate) error
+    RequestPayout(ctx context.Context, childID string, gold, amountMinor int, currency string) (*model.Payout, error)
+    UpdatePayout(ctx context.Context, parentID, payoutID string, to model.PayoutStatus) (*model.Payout, error)
+    ListPayouts(ctx context.Context, parentID string) ([]*model.Payout, error)
+    ListChildPayouts(ctx context.Context, childID string) ([]*model.Payout, error)
+
+    // Savings goals: gold moved out of the child's spendable balance, plus an
+    // optional weekly bonus rule applied once per goal and week.
+    CreateSavingsGoal(ctx context.Context, parentID, childID, name string, target int) (*model.SavingsGoal, error)
+    ListSavingsGoals(ctx context.Context, childID string) ([]*model.SavingsGoal, error)
+    GetSavingsGoal(ctx context.Context, goalID string) (*model.SavingsGoal, error)
+    MoveSavings(ctx context.Context, goalID string, gold int) (*model.SavingsGoal, error)
+    // UpdateSavingsGoal sets a goal's options. week is the start of the current
+    // week; a rule added to a goal without one only earns for weeks after it.
+    UpdateSavingsGoal(ctx context.Context, goalID string, allowEarly bool, rule *model.SavingsRule, week string) (*model.SavingsGoal, error)
+    SavingsGoalsWithRules(ctx context.Context) ([]*SavingsAccrual, error)
+    ApplySavingsBonus(ctx context.Context, a *SavingsAccrual, week string, bonus int) (applied bool, err error)
+
+    // Gold transfers between children of a household; the approval threshold
+    // is nil when no transfer needs approval.
+    GetTransferThreshold(ctx context.Context, parentID string) (*int, error)
+    SetTransferThreshold(ctx context.Context, parentID string, gold *int) error
+    TransferGold(ctx context.Context, fromChildID, toChildID string, gold int, note *string, pending bool) (*model.GoldTransfer, error)
+    DecideTransfer(ctx context.Context, parentID, transferID string, approve bool) (*model.GoldTransfer, error)
+    ListTransfers(ctx context.Context, parentID string) ([]*model.GoldTransfer, error)
+
+    // Spending limits per child, the household's approval item list and
+    // purchases held for approval.
+    GetSpendingLimits(ctx context.Context, childID string) (*model.SpendingLimits, error)
+    SetSpendingLimits(ctx context.Context, parentID string, l *model.SpendingLimits) error
+    GetApprovalItems(ctx context.Context, parentID string) ([]string, error)
+    SetApprovalItems(ctx context.Context, parentID string, names []string) error
+    HoldPurchase(ctx context.Context, childID, itemName string, priceGold int, reason model.PurchaseHoldReason) (*model.PurchaseRequest, *model.Child, error)
+    DecidePurchase(ctx context.Context, parentID, requestID string, approve bool) (*model.PurchaseRequest, error)
+    ListPurchaseRequests(ctx context.Context, parentID string) ([]*model.PurchaseRequest, error)
+    ListChildPurchaseRequests(ctx context.Context, childID string) ([]*model.PurchaseRequest, error)
+
+    // Parent-owned quest templates and shared template sets.
+    CreateQuestTemplate(ctx context.Context, t *model.QuestTemplate) (*model.QuestTemplate, error)
+    ListQuestTemplates(ctx context.Context, parentID string) ([]*model.QuestTemplate, error)
+    DeleteQuestTemplate(ctx context.Context, parentID, templateID string) (*model.QuestTemplate, error)
+    ShareQuestTemplates(ctx context.Context, parentID, code string, ts []*model.QuestTemplate, expires time.Time) error
+    SharedQuestTemplates(ctx context.Context, code string) ([]*model.QuestTemplate, error)
+
+    // Quest details and the household's difficulty multipliers (nil when unset).
+    UpdateQuestDetails(ctx context.Context, questID string, in model.QuestDetailsInput) (*model.Quest, error)
+    GetDifficultyMultipliers(ctx context.Context, parentID string) (*model.DifficultyMultipliers, error)
+    SetDifficultyMultipliers(ctx context.Context, parentID string, m *model.DifficultyMultipliers) error
+}
+
+// OutboxEntry is a committed event waiting to be published. Attempts counts
+// the leases taken on it, including the current one once leased.
+type OutboxEntry struct {
+    Event    events.Event
+    Attempts int
+    lease    string
+}
+
+// Billing is a parent's Stripe state. Status is Stripe's subscription status
+// and empty before the first checkout.
+type Billing struct {
+    CustomerID        string
+    SubscriptionID    string
+    Status            string
+    CurrentPeriodEnd  *string
+    CancelAtPeriodEnd bool
+    PaymentFailedAt   *string
+}
+
+// Active reports whether the subscription currently grants premium; past_due
+// counts while Stripe retries the payment.
+func (b *Billing) Active() bool {
+    switch b.Status {
+    case "active", "trialing", "past_due":
+        return true
+    }
+    return false
+}
+
+// SavingsAccrual is a goal with a bonus rule, the deposits counted for a MATCH
+// rule and the last week a bonus was applied.
+type SavingsAccrual struct {
+    Goal     *model.SavingsGoal
+    Deposits int
+    Week     string
+}
+
+// PushTarget is a push subscription with the keys its messages are encrypted for.
+type PushTarget struct {
+    Subscription *model.PushSubscription
+    P256dh       string
+    Auth         string
+}
+
+// WebhookJob is a pending delivery with the event body to send.
+type WebhookJob struct {
+    Delivery *model.WebhookDelivery
+    Payload  []byte
+}
+
+// IdempotencyRecord is a stored mutation result keyed by caller and Idempotency-Key.
+// Response is empty while the original request is still executing.
+type IdempotencyRecord struct {
+    Fingerprint string
+    Response    []byte
+    Claim       string
+}
+
+// Login is a parent's email/password credential.
+type Login struct {
+    ParentID     string
+    PasswordHash string
+}
+
+// MFAState is a parent's TOTP enrollment. Secret is set once enrollment starts;
+// EnabledAt only after the first code is verified.
+type MFAState struct {
+    Secret         string
+    EnabledAt      *string
+    RecoveryHashes []string
 }
 
 // NowRFC3339 returns a UTC RFC3339 timestamp.
@@ -0,0 +1,130 @@
+// Package report builds the weekly household progress report shared by the
+// weeklyReport query and the emailed digest.
+package report
+
+import (
+    "context"
+    "time"
+
+    "chorequest/backend/graph/model"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// OverdueAfter is how long an assignment may stay open before the report
+// counts it as overdue; assignments have no due date of their own.
+const OverdueAfter = 72 * time.Hour
+
+// WeekStart returns Monday 00:00 UTC of the week containing t.
+func WeekStart(t time.Time) time.Time {
+    t = t.UTC()
+    d := (int(t.Weekday()) + 6) % 7
+    return time.Date(t.Year(), t.Month(), t.Day()-d, 0, 0, 0, 0, time.UTC)
+}
+
+// Weekly builds the report for the week starting at week (a Monday, see
+// WeekStart). now bounds streaks for the current week.
+func Weekly(ctx context.Context, store repopkg.Repo, parentID string, week, now time.Time) (*model.WeeklyReport, error) {
+    end := week.AddDate(0, 0, 7)
+    rep := &model.WeeklyReport{
+        ParentID:  parentID,
+        WeekStart: week.Format(time.DateOnly),
+        WeekEnd:   end.AddDate(0, 0, -1).Format(time.DateOnly),
+        Children:  []*model.ChildWeeklyReport{},
+    }
+    var all []*model.Assignment
+    children, err := store.ListChildren(ctx, parentID)
+    if err != nil { return nil, err }
+    for _, c := range children {
+        cr, err := childWeek(ctx, store, c, week, end, now)
+        if err != nil { return nil, err }
+        rep.Children = append(rep.Children, cr)
+        rep.QuestsCompleted += cr.QuestsCompleted
+        rep.XpEarned += cr.XpEarned
+        rep.GoldEarned += cr.GoldEarned
+        rep.GoldSpent += cr.GoldSpent
+        all = append(all, cr.Completed...)
+    }
+    rep.CompletedByCategory = byCategory(all)
+    return rep, nil
+}
+
+func childWeek(ctx context.Context, store repopkg.Repo, c *model.Child, week, end, now time.Time) (*model.ChildWeeklyReport, error) {
+    from, to := week.Format(time.RFC3339), end.Format(time.RFC3339)
+    overdueBefore := end.Add(-OverdueAfter).Format(time.RFC3339)
+    cr := &model.ChildWeeklyReport{Child: c, Completed: []*model.Assignment{}, OverdueChores: []*model.Assignment{}}
+    as, err := store.ListAssignmentsForChild(ctx, c.ID)
+    if err != nil { return nil, err }
+    days := map[string]bool{}
+    for _, a := range as {
+        if a.CompletedAt != nil { days[(*a.CompletedAt)[:len(time.DateOnly)]] = true }
+        switch {
+        case a.CompletedAt != nil && *a.CompletedAt >= from && *a.CompletedAt < to:
+            cr.Completed = append(cr.Completed, a)
+            cr.QuestsCompleted++
+            cr.XpEarned += a.EarnedXp()
+            cr.GoldEarned += a.EarnedGold()
+        case (a.CompletedAt == nil || *a.CompletedAt >= to) && a.CreatedAt < overdueBefore:
+            cr.OverdueChores = append(cr.OverdueChores, a)
+        }
+    }
+    purchases, err := store.ListPurchases(ctx, c.ID, from, to)
+    if err != nil { return nil, err }
+    cr.Purchases = purchases
+    for _, p := range purchases {
+        cr.GoldSpent += p.PriceGold
+    }
+    cr.CompletedByCategory = byCategory(cr.Completed)
+    cr.StreakDays, cr.LongestStreakDays = streaks(days, week, end, now)
+    return cr, nil
+}
+
+// byCategory totals completed assignments by their quest's current category,
+// in the order of model.AllQuestCategory with uncategorized quests last.
+func byCategory(completed []*model.Assignment) []*model.CategoryCount {
+    const none = model.QuestCategory("")
+    counts := map[model.QuestCategory]*model.CategoryCount{}
+    for _, a := range completed {
+        cat := none
+        if a.Quest != nil && a.Quest.Category != nil { cat = *a.Quest.Category }
+        c := counts[cat]
+        if c == nil {
+            c = &model.CategoryCount{}
+            if cat != none { c.Category = &cat }
+            counts[cat] = c
+        }
+        c.Completed++
+        c.XpEarned += a.EarnedXp()
+        c.GoldEarned += a.EarnedGold()
+    }
+    out := []*model.CategoryCount{}
+    for _, cat := range model.AllQuestCategory {
+        if c := counts[cat]; c != nil { out = append(out, c) }
+    }
+    if c := counts[none]; c != nil { out = append(out, c) }
+    return out
+}
+
+// streaks returns the run of completion days ending on the week's last day
+// (or today, for the current week; a today without completions yet does not
+// break the run) and the longest run inside the week.
+func streaks(days map[string]bool, week, end, now time.Time) (current, longest int) {
+    day := end.AddDate(0, 0, -1)
+    n := now.UTC()
+    if today := time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, time.UTC); today.Before(day) {
+        day = today
+        if !days[day.Format(time.DateOnly)] { day = day.AddDate(0, 0, -1) }
+    }
+    for d := day; days[d.Format(time.DateOnly)]; d = d.AddDate(0, 0, -1) {
+        current++
+    }
+    run := 0
+    for d := week; d.Before(end); d = d.AddDate(0, 0, 1) {
+        if days[d.Format(time.DateOnly)] {
+            run++
+            longest = max(longest, run)
+        } else {
+            run = 0
+        }
+    }
+    return current, longest
+}
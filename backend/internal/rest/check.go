@@ -0,0 +1,144 @@
+package rest
+
+import (
+    "context"
+    "encoding/json"
+    "strconv"
+
+    "github.com/99designs/gqlgen/graphql"
+    "github.com/vektah/gqlparser/v2/ast"
+
+    "chorequest/backend/graph"
+    "chorequest/backend/internal/apperr"
+)
+
+// check validates v against a GraphQL input type and its directives, and
+// returns it in the shape resolvers expect (json.Number becomes int). Query
+// string values arrive as text, so numbers and booleans may be strings.
+// Directive errors come from the same @range/@length implementations as
+// GraphQL, with path as the reported field.
+func (a *API) check(ctx context.Context, path string, t *ast.Type, dirs ast.DirectiveList, v any) (any, error) {
+    if v == nil {
+        if t.NonNull { return nil, apperr.Invalid(fieldName(path), "is required") }
+        return nil, nil
+    }
+    if t.Elem != nil {
+        list, ok := v.([]any)
+        if !ok { return nil, apperr.Invalid(fieldName(path), "must be a list") }
+        out := make([]any, len(list))
+        for i, e := range list {
+            ev, err := a.check(ctx, join(path, strconv.Itoa(i)), t.Elem, nil, e)
+            if err != nil { return nil, err }
+            out[i] = ev
+        }
+        return out, nil
+    }
+
+    def := a.schema.Types[t.NamedType]
+    var out any
+    switch {
+    case def == nil:
+        return nil, apperr.Invalid(fieldName(path), "has unknown type %s", t.NamedType)
+    case def.Kind == ast.InputObject:
+        obj, ok := v.(map[string]any)
+        if !ok { return nil, apperr.Invalid(fieldName(path), "must be an object") }
+        for k := range obj {
+            if def.Fields.ForName(k) == nil { return nil, apperr.Invalid(join(path, k), "unknown field") }
+        }
+        m := map[string]any{}
+        for _, f := range def.Fields {
+            fv, err := a.check(ctx, join(path, f.Name), f.Type, f.Directives, obj[f.Name])
+            if err != nil { return nil, err }
+            if fv != nil { m[f.Name] = fv }
+        }
+        out = m
+    case def.Kind == ast.Enum:
+        s, ok := v.(string)
+        if !ok || def.EnumValues.ForName(s) == nil { return nil, apperr.Invalid(fieldName(path), "must be one of %s", enumNames(def)) }
+        out = s
+    case t.NamedType == "Int":
+        n, ok := intValue(v)
+        if !ok { return nil, apperr.Invalid(fieldName(path), "must be an integer") }
+        out = n
+    case t.NamedType == "Boolean":
+        switch b := v.(type) {
+        case bool:
+            out = b
+        case string:
+            pb, err := strconv.ParseBool(b)
+            if err != nil { return nil, apperr.Invalid(fieldName(path), "must be true or false") }
+            out = pb
+        default:
+            return nil, apperr.Invalid(fieldName(path), "must be true or false")
+        }
+    default:
+        s, ok := v.(string)
+        if !ok { return nil, apperr.Invalid(fieldName(path), "must be a string") }
+        out = s
+    }
+    return applyDirectives(ctx, path, dirs, out)
+}
+
+// applyDirectives runs v through the field's directives and returns the value
+// they pass on (@length trims strings).
+func applyDirectives(ctx context.Context, path string, dirs ast.DirectiveList, v any) (any, error) {
+    ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField(fieldName(path)))
+    for _, d := range dirs {
+        min, max := intArg(d, "min"), intArg(d, "max")
+        next := func(context.Context) (any, error) { return v, nil }
+        var err error
+        switch d.Name {
+        case "range":
+            v, err = graph.Range(ctx, nil, next, min, max)
+        case "length":
+            v, err = graph.Length(ctx, nil, next, min, max)
+        }
+        if err != nil { return nil, err }
+    }
+    return v, nil
+}
+
+func intValue(v any) (int, bool) {
+    var s string
+    switch t := v.(type) {
+    case json.Number:
+        s = t.String()
+    case string:
+        s = t
+    default:
+        return 0, false
+    }
+    n, err := strconv.Atoi(s)
+    return n, err == nil
+}
+
+func intArg(d *ast.Directive, name string) *int {
+    arg := d.Arguments.ForName(name)
+    if arg == nil { return nil }
+    v, err := arg.Value.Value(nil)
+    if err != nil { return nil }
+    n, ok := v.(int64)
+    if !ok { return nil }
+    i := int(n)
+    return &i
+}
+
+func enumNames(def *ast.Definition) string {
+    var s string
+    for i, e := range def.EnumValues {
+        if i > 0 { s += ", " }
+        s += e.Name
+    }
+    return s
+}
+
+func join(path, name string) string {
+    if path == "" { return name }
+    return path + "." + name
+}
+
+// fieldName names the whole request body when path is empty.
+func fieldName(path string) string {
+    if path == "" { return "body" }
+    return path
+}
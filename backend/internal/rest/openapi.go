@@ -0,0 +1,196 @@
+package rest
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "regexp"
+    "strings"
+
+    "github.com/vektah/gqlparser/v2/ast"
+
+    "chorequest/backend/internal/apperr"
+)
+
+type obj = map[string]any
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPI renders an OpenAPI 3.0 document for routes. Parameter, body and
+// response schemas, including @range/@length limits, come from the GraphQL
+// schema so the two APIs cannot drift apart.
+func (a *API) openAPI() ([]byte, error) {
+    g := &specGen{schema: a.schema, components: obj{}}
+    paths := obj{}
+    for _, rt := range routes {
+        def := a.fieldDef(rt.field)
+        if def == nil { return nil, fmt.Errorf("rest: %s %s: unknown field %s", rt.method, rt.path, rt.field) }
+        item, _ := paths[rt.path].(obj)
+        if item == nil {
+            item = obj{}
+            paths[rt.path] = item
+        }
+        item[strings.ToLower(rt.method)] = g.operation(rt, def)
+    }
+    g.components["Error"] = obj{
+        "type":     "object",
+        "required": []string{"error"},
+        "properties": obj{
+            "error": obj{
+                "type":     "object",
+                "required": []string{"code", "message"},
+                "properties": obj{
+                    "code":    obj{"type": "string", "enum": []apperr.Code{apperr.NotFound, apperr.InsufficientFunds, apperr.AlreadyCompleted, apperr.Forbidden, apperr.Validation, apperr.Conflict, apperr.RateLimited, apperr.Internal}},
+                    "message": obj{"type": "string"},
+                    "field":   obj{"type": "string", "description": "Offending parameter or body field for VALIDATION errors."},
+                },
+            },
+        },
+    }
+    return json.MarshalIndent(obj{
+        "openapi": "3.0.3",
+        "info": obj{
+            "title":       "ChoreQuest API",
+            "version":     "v1",
+            "description": "REST mirror of a subset of the GraphQL API at /query. Authenticate with a parent JWT or an API key; API key scopes apply as on GraphQL.",
+        },
+        "servers": []obj{{"url": "/api/v1"}},
+        "paths":   paths,
+        "components": obj{
+            "schemas": g.components,
+            "securitySchemes": obj{
+                "bearer": obj{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+                "apiKey": obj{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+            },
+        },
+        "security": []obj{{"bearer": []string{}}, {"apiKey": []string{}}},
+    }, "", "  ")
+}
+
+type specGen struct {
+    schema     *ast.Schema
+    components obj
+}
+
+func (g *specGen) operation(rt route, def *ast.FieldDefinition) obj {
+    inPath := map[string]bool{}
+    for _, m := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+        inPath[m[1]] = true
+    }
+    var params []obj
+    var body []*ast.ArgumentDefinition
+    for _, arg := range def.Arguments {
+        switch {
+        case inPath[arg.Name]:
+            params = append(params, g.param(arg, "path"))
+        case rt.method == http.MethodGet:
+            params = append(params, g.param(arg, "query"))
+        default:
+            body = append(body, arg)
+        }
+    }
+    op := obj{
+        "operationId": def.Name,
+        "summary":     rt.summary,
+        "tags":        []string{strings.Trim(strings.SplitN(rt.path, "/", 3)[1], "/")},
+        "responses": obj{
+            fmt.Sprint(rt.status): obj{
+                "description": http.StatusText(rt.status),
+                "content":     obj{"application/json": obj{"schema": g.schemaFor(def.Type, nil)}},
+            },
+            "default": obj{
+                "description": "Error",
+                "content":     obj{"application/json": obj{"schema": ref("Error")}},
+            },
+        },
+    }
+    if def.Description != "" { op["description"] = def.Description }
+    if len(params) > 0 { op["parameters"] = params }
+    if len(body) > 0 {
+        var s obj
+        if len(body) == 1 && g.schema.Types[body[0].Type.Name()].Kind == ast.InputObject {
+            s = g.schemaFor(body[0].Type, nil)
+        } else {
+            props, required := obj{}, []string{}
+            for _, arg := range body {
+                props[arg.Name] = g.schemaFor(arg.Type, arg.Directives)
+                if arg.Type.NonNull { required = append(required, arg.Name) }
+            }
+            s = obj{"type": "object", "properties": props, "additionalProperties": false}
+            if len(required) > 0 { s["required"] = required }
+        }
+        op["requestBody"] = obj{"required": true, "content": obj{"application/json": obj{"schema": s}}}
+    }
+    return op
+}
+
+func (g *specGen) param(arg *ast.ArgumentDefinition, in string) obj {
+    p := obj{"name": arg.Name, "in": in, "required": in == "path" || arg.Type.NonNull, "schema": g.schemaFor(arg.Type, arg.Directives)}
+    if arg.Description != "" { p["description"] = arg.Description }
+    return p
+}
+
+// schemaFor converts a GraphQL type reference to a JSON schema. Object, input
+// and enum types become shared components.
+func (g *specGen) schemaFor(t *ast.Type, dirs ast.DirectiveList) obj {
+    if t.Elem != nil {
+        return obj{"type": "array", "items": g.schemaFor(t.Elem, nil)}
+    }
+    var s obj
+    switch t.NamedType {
+    case "Int":
+        s = obj{"type": "integer"}
+    case "Float":
+        s = obj{"type": "number"}
+    case "Boolean":
+        s = obj{"type": "boolean"}
+    case "String", "ID":
+        s = obj{"type": "string"}
+    default:
+        g.component(t.NamedType)
+        return ref(t.NamedType)
+    }
+    for _, d := range dirs {
+        min, max := intArg(d, "min"), intArg(d, "max")
+        lo, hi := "minimum", "maximum"
+        if d.Name == "length" {
+            lo, hi = "minLength", "maxLength"
+        } else if d.Name != "range" {
+            continue
+        }
+        if min != nil { s[lo] = *min }
+        if max != nil { s[hi] = *max }
+    }
+    return s
+}
+
+func (g *specGen) component(name string) {
+    if _, ok := g.components[name]; ok { return }
+    def := g.schema.Types[name]
+    if def == nil { return }
+    if def.Kind == ast.Enum {
+        var vals []string
+        for _, v := range def.EnumValues {
+            vals = append(vals, v.Name)
+        }
+        g.components[name] = obj{"type": "string", "enum": vals}
+        return
+    }
+    // Reserve the name first so recursive types terminate.
+    s := obj{"type": "object"}
+    g.components[name] = s
+    props, required := obj{}, []string{}
+    for _, f := range def.Fields {
+        if strings.HasPrefix(f.Name, "__") { continue }
+        p := g.schemaFor(f.Type, f.Directives)
+        if f.Description != "" && p["$ref"] == nil { p["description"] = f.Description }
+        props[f.Name] = p
+        if f.Type.NonNull { required = append(required, f.Name) }
+    }
+    s["properties"] = props
+    if len(required) > 0 { s["required"] = required }
+    if def.Kind == ast.InputObject { s["additionalProperties"] = false }
+    if def.Description != "" { s["description"] = def.Description }
+}
+
+func ref(name string) obj { return obj{"$ref": "#/components/schemas/" + name} }
@@ -0,0 +1,373 @@
+// Package rest serves a small versioned JSON API for integrations that cannot
+// speak GraphQL. Handlers call the GraphQL resolvers, so authorization,
+// validation and events behave exactly as on /query, and the OpenAPI document
+// is derived from the GraphQL schema.
+package rest
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "io"
+    "log"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/vektah/gqlparser/v2/ast"
+
+    "chorequest/backend/graph"
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/ratelimit"
+)
+
+const maxBody = 1 << 20
+
+// route maps an HTTP operation onto a GraphQL field. Path parameters and, for
+// GET, query parameters fill the field's arguments by name. Other methods read
+// the remaining arguments from a JSON object body, or take the body as the
+// whole argument when the field has a single input object argument.
+type route struct {
+    method  string
+    path    string
+    field   string // "Query.children"
+    summary string
+    status  int
+    call    func(ctx context.Context, a *API, in args) (any, error)
+}
+
+var routes = []route{
+    {
+        method: "GET", path: "/children", field: "Query.children", status: http.StatusOK,
+        summary: "List the children of a household",
+        call: func(ctx context.Context, a *API, in args) (any, error) { return a.query.Children(ctx, in.str("parentId")) },
+    },
+    {
+        method: "POST", path: "/children", field: "Mutation.createChild", status: http.StatusCreated,
+        summary: "Add a child",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            var v model.NewChild
+            if err := in.decode("input", &v); err != nil { return nil, err }
+            return a.mutation.CreateChild(ctx, v)
+        },
+    },
+    {
+        method: "GET", path: "/children/{childId}/assignments", field: "Query.myAssignments", status: http.StatusOK,
+        summary: "List a child's assignments",
+        call: func(ctx context.Context, a *API, in args) (any, error) { return a.query.MyAssignments(ctx, in.str("childId")) },
+    },
+    {
+        method: "POST", path: "/children/{childId}/purchases", field: "Mutation.purchaseItem", status: http.StatusOK,
+        summary: "Spend a child's gold on an item",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            return a.mutation.PurchaseItem(ctx, in.str("childId"), in.str("itemName"), in.int("priceGold"))
+        },
+    },
+    {
+        method: "GET", path: "/quests", field: "Query.quests", status: http.StatusOK,
+        summary: "List a household's quests, optionally filtered by category, tag or difficulty",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            var category *model.QuestCategory
+            var tag *string
+            var difficulty *model.Difficulty
+            if err := in.decode("category", &category); err != nil { return nil, err }
+            if err := in.decode("tag", &tag); err != nil { return nil, err }
+            if err := in.decode("difficulty", &difficulty); err != nil { return nil, err }
+            return a.query.Quests(ctx, in.str("parentId"), category, tag, difficulty)
+        },
+    },
+    {
+        method: "POST", path: "/quests", field: "Mutation.createQuest", status: http.StatusCreated,
+        summary: "Create a quest",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            var v model.NewQuest
+            if err := in.decode("input", &v); err != nil { return nil, err }
+            return a.mutation.CreateQuest(ctx, v)
+        },
+    },
+    {
+        method: "POST", path: "/quests/{questId}/assign", field: "Mutation.assignQuest", status: http.StatusCreated,
+        summary: "Assign a quest to a child",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            return a.mutation.AssignQuest(ctx, in.str("questId"), in.str("childId"))
+        },
+    },
+    {
+        method: "GET", path: "/rewards", field: "Query.rewards", status: http.StatusOK,
+        summary: "List a household's rewards",
+        call: func(ctx context.Context, a *API, in args) (any, error) { return a.query.Rewards(ctx, in.str("parentId")) },
+    },
+    {
+        method: "POST", path: "/rewards", field: "Mutation.createReward", status: http.StatusCreated,
+        summary: "Create a reward",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            var v model.NewReward
+            if err := in.decode("input", &v); err != nil { return nil, err }
+            return a.mutation.CreateReward(ctx, v)
+        },
+    },
+    {
+        method: "POST", path: "/assignments/{assignmentId}/complete", field: "Mutation.completeAssignment", status: http.StatusOK,
+        summary: "Complete an assignment and pay out its XP and gold",
+        call: func(ctx context.Context, a *API, in args) (any, error) {
+            return a.mutation.CompleteAssignment(ctx, in.str("assignmentId"))
+        },
+    },
+}
+
+// API is the /api/v1 handler set.
+type API struct {
+    // Idempotency makes writes carrying an Idempotency-Key safe to retry, with
+    // the same store and rules as GraphQL mutations.
+    Idempotency graph.Idempotency
+    // WriteLimit, when set, spends the caller's GraphQL mutation budget for
+    // each write; ok is false once it is empty.
+    WriteLimit func(r *http.Request) (retryAfter time.Duration, ok bool)
+
+    query    graph.QueryResolver
+    mutation graph.MutationResolver
+    schema   *ast.Schema
+    spec     []byte
+}
+
+// New builds the API over resolver and renders its OpenAPI document.
+func New(resolver *graph.Resolver) (*API, error) {
+    a := &API{
+        query:    resolver.Query(),
+        mutation: resolver.Mutation(),
+        schema:   graph.NewExecutableSchema(graph.NewConfig(resolver)).Schema(),
+    }
+    spec, err := a.openAPI()
+    if err != nil { return nil, err }
+    a.spec = spec
+    return a, nil
+}
+
+// Handler returns a router meant to be mounted at /api/v1.
+func (a *API) Handler() http.Handler {
+    r := chi.NewRouter()
+    r.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "application/json")
+        _, _ = w.Write(a.spec)
+    })
+    for _, rt := range routes {
+        def := a.fieldDef(rt.field)
+        r.Method(rt.method, rt.path, a.serve(rt, def))
+    }
+    r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+        writeError(w, apperr.New(apperr.NotFound, "no such endpoint"))
+    })
+    return r
+}
+
+// fieldDef looks up "Type.field" in the schema; routes are static, so a
+// missing field is a programming error caught by New.
+func (a *API) fieldDef(name string) *ast.FieldDefinition {
+    typ, field, _ := strings.Cut(name, ".")
+    if def := a.schema.Types[typ]; def != nil {
+        return def.Fields.ForName(field)
+    }
+    return nil
+}
+
+func (a *API) serve(rt route, def *ast.FieldDefinition) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        in, err := a.readArgs(r, def)
+        if err != nil { writeError(w, err); return }
+        if rt.method == http.MethodGet {
+            out, err := rt.call(r.Context(), a, in)
+            if err != nil { writeError(w, err); return }
+            writeJSON(w, rt.status, out)
+            return
+        }
+        a.write(w, r, rt, in)
+    }
+}
+
+// stored is a write's response as kept for Idempotency-Key replays.
+type stored struct {
+    Status int             `json:"status"`
+    Body   json.RawMessage `json:"body"`
+}
+
+// write runs a state-changing route like a GraphQL mutation: it spends the
+// mutation budget, then executes at most once per Idempotency-Key. Only
+// successes and validation failures are kept for replay.
+func (a *API) write(w http.ResponseWriter, r *http.Request, rt route, in args) {
+    if a.WriteLimit != nil {
+        if wait, ok := a.WriteLimit(r); !ok {
+            w.Header().Set("Retry-After", ratelimit.RetryAfterSeconds(wait))
+            writeError(w, apperr.New(apperr.RateLimited, "too many requests, retry in %ss", ratelimit.RetryAfterSeconds(wait)))
+            return
+        }
+    }
+    respond := func() stored {
+        out, err := rt.call(r.Context(), a, in)
+        if err != nil {
+            status, body := errorResponse(err)
+            return stored{Status: status, Body: body}
+        }
+        body, err := json.Marshal(out)
+        if err != nil {
+            status, body := errorResponse(err)
+            return stored{Status: status, Body: body}
+        }
+        return stored{Status: rt.status, Body: body}
+    }
+    key := strings.TrimSpace(r.Header.Get(graph.IdempotencyHeader))
+    if key == "" {
+        res := respond()
+        writeRaw(w, res.Status, res.Body)
+        return
+    }
+    params, _ := json.Marshal(in)
+    sum := sha256.Sum256([]byte(rt.method + " " + r.URL.Path + "\x00" + string(params)))
+    var res stored
+    body, replayed, err := a.Idempotency.Run(r.Context(), key, hex.EncodeToString(sum[:]), func() ([]byte, bool) {
+        res = respond()
+        if res.Status >= 400 && res.Status != http.StatusBadRequest { return nil, false }
+        b, err := json.Marshal(res)
+        return b, err == nil
+    })
+    if err != nil { writeError(w, err); return }
+    if replayed {
+        if err := json.Unmarshal(body, &res); err != nil { writeError(w, err); return }
+        w.Header().Set("Idempotent-Replay", "true")
+    }
+    writeRaw(w, res.Status, res.Body)
+}
+
+// readArgs collects the field's arguments from the path, query string or body
+// and checks them against the schema, including @range/@length.
+func (a *API) readArgs(r *http.Request, def *ast.FieldDefinition) (args, error) {
+    raw := map[string]any{}
+    var rest ast.ArgumentDefinitionList
+    for _, arg := range def.Arguments {
+        if v := chi.URLParam(r, arg.Name); v != "" {
+            raw[arg.Name] = v
+        } else if r.Method == http.MethodGet {
+            if v := r.URL.Query().Get(arg.Name); v != "" { raw[arg.Name] = v }
+        } else {
+            rest = append(rest, arg)
+        }
+    }
+    if len(rest) > 0 {
+        body, err := readBody(r)
+        if err != nil { return nil, err }
+        if len(rest) == 1 && a.schema.Types[rest[0].Type.Name()].Kind == ast.InputObject {
+            raw[rest[0].Name] = body
+        } else {
+            for k, v := range body {
+                if rest.ForName(k) == nil { return nil, apperr.Invalid(k, "unknown field") }
+                raw[k] = v
+            }
+        }
+    }
+    in := args{}
+    for _, arg := range def.Arguments {
+        // A body standing in for an input object reports its own field names.
+        path := arg.Name
+        if len(rest) == 1 && rest[0] == arg {
+            path = ""
+        }
+        v, err := a.check(r.Context(), path, arg.Type, arg.Directives, raw[arg.Name])
+        if err != nil { return nil, err }
+        in[arg.Name] = v
+    }
+    return in, nil
+}
+
+func readBody(r *http.Request) (map[string]any, error) {
+    body := map[string]any{}
+    dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBody))
+    dec.UseNumber()
+    if err := dec.Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+        return nil, apperr.Invalid("body", "must be a JSON object")
+    }
+    return body, nil
+}
+
+// args holds checked argument values: strings, ints, bools, lists and maps.
+type args map[string]any
+
+func (in args) str(name string) string {
+    s, _ := in[name].(string)
+    return s
+}
+
+func (in args) int(name string) int {
+    n, _ := in[name].(int)
+    return n
+}
+
+// decode converts an input object argument into its model type.
+func (in args) decode(name string, dst any) error {
+    b, err := json.Marshal(in[name])
+    if err != nil { return err }
+    return json.Unmarshal(b, dst)
+}
+
+type errorBody struct {
+    Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+    Code    apperr.Code `json:"code"`
+    Message string      `json:"message"`
+    Field   string      `json:"field,omitempty"`
+}
+
+// writeError reports err like the GraphQL error presenter: domain errors keep
+// their code and message, anything else is logged and hidden as INTERNAL.
+func writeError(w http.ResponseWriter, err error) {
+    status, body := errorResponse(err)
+    writeRaw(w, status, body)
+}
+
+func errorResponse(err error) (int, []byte) {
+    var ae *apperr.Error
+    if !errors.As(err, &ae) {
+        log.Printf("rest: %v", err)
+        ae = apperr.New(apperr.Internal, "internal error")
+    }
+    body, _ := json.Marshal(errorBody{Error: errorDetail{Code: ae.Code, Message: ae.Message, Field: ae.Field}})
+    return statusOf(ae.Code), body
+}
+
+func statusOf(code apperr.Code) int {
+    switch code {
+    case apperr.NotFound:
+        return http.StatusNotFound
+    case apperr.Forbidden:
+        return http.StatusForbidden
+    case apperr.Validation:
+        return http.StatusBadRequest
+    case apperr.InsufficientFunds, apperr.AlreadyCompleted, apperr.Conflict:
+        return http.StatusConflict
+    case apperr.RateLimited:
+        return http.StatusTooManyRequests
+    case apperr.PlanLimit:
+        return http.StatusPaymentRequired
+    }
+    return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+    b, err := json.Marshal(v)
+    if err != nil {
+        log.Printf("rest: encode response: %v", err)
+        http.Error(w, "internal error", http.StatusInternalServerError)
+        return
+    }
+    writeRaw(w, status, b)
+}
+
+func writeRaw(w http.ResponseWriter, status int, body []byte) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    _, _ = w.Write(body)
+}
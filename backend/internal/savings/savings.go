@@ -0,0 +1,65 @@
+// Package savings applies the weekly bonus rules of savings goals.
+package savings
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/report"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// Scheduler credits each goal's rule once per week. Every server instance may
+// run one; the bonus update is conditional on the week, so it applies once.
+type Scheduler struct {
+    Store    repopkg.Repo
+    Interval time.Duration
+}
+
+func NewScheduler(store repopkg.Repo) *Scheduler {
+    return &Scheduler{Store: store, Interval: time.Hour}
+}
+
+// Run applies bonuses at startup and then every Interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+    tick := time.NewTicker(s.Interval)
+    defer tick.Stop()
+    for {
+        s.ApplyWeekly(ctx, time.Now())
+        select {
+        case <-ctx.Done():
+            return
+        case <-tick.C:
+        }
+    }
+}
+
+// ApplyWeekly credits the bonus for the week before now (Monday-Sunday, UTC)
+// to every goal that has not had it yet. Reached goals earn nothing more.
+func (s *Scheduler) ApplyWeekly(ctx context.Context, now time.Time) {
+    week := report.WeekStart(now).AddDate(0, 0, -7).Format(time.DateOnly)
+    goals, err := s.Store.SavingsGoalsWithRules(ctx)
+    if err != nil {
+        log.Printf("savings: list goals: %v", err)
+        return
+    }
+    for _, a := range goals {
+        g := a.Goal
+        if g.Rule == nil || g.ReachedAt != nil || a.Week >= week { continue }
+        if _, err := s.Store.ApplySavingsBonus(ctx, a, week, Bonus(g.Rule, g.SavedGold, a.Deposits)); err != nil {
+            log.Printf("savings: bonus for goal %s: %v", g.ID, err)
+        }
+    }
+}
+
+// Bonus is a week's bonus under rule: percent of the saved gold for INTEREST
+// or of the week's deposits for MATCH, rounded down and capped.
+func Bonus(rule *model.SavingsRule, saved, deposits int) int {
+    base := saved
+    if rule.Kind == model.SavingsRuleKindMatch { base = deposits }
+    b := base * rule.Percent / 100
+    if rule.MaxGoldPerWeek != nil && b > *rule.MaxGoldPerWeek { b = *rule.MaxGoldPerWeek }
+    return b
+}
@@ -0,0 +1,89 @@
+// Package schedule assigns recurring quests when their schedules come due.
+// Every server instance may run a Runner; a run is claimed by advancing the
+// schedule conditionally, so each run is executed once.
+package schedule
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/entitlements"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+type Runner struct {
+    Store repopkg.Repo
+    Plans *entitlements.Checker
+
+    PollInterval time.Duration
+    BatchSize    int
+}
+
+func NewRunner(store repopkg.Repo, plans *entitlements.Checker) *Runner {
+    return &Runner{Store: store, Plans: plans, PollInterval: time.Minute, BatchSize: 25}
+}
+
+// Run polls for due schedules until ctx is cancelled.
+func (r *Runner) Run(ctx context.Context) {
+    tick := time.NewTicker(r.PollInterval)
+    defer tick.Stop()
+    for {
+        r.RunDue(ctx, time.Now())
+        select {
+        case <-ctx.Done():
+            return
+        case <-tick.C:
+        }
+    }
+}
+
+// RunDue executes the schedules due at now. Failures are logged; the failed
+// run is not retried, the schedule continues with its next one.
+func (r *Runner) RunDue(ctx context.Context, now time.Time) {
+    due, err := r.Store.DueQuestSchedules(ctx, now, r.BatchSize)
+    if err != nil {
+        log.Printf("schedule: poll: %v", err)
+        return
+    }
+    for _, s := range due {
+        if err := r.run(ctx, s, now); err != nil {
+            log.Printf("schedule %s: %v", s.ID, err)
+        }
+    }
+}
+
+func (r *Runner) run(ctx context.Context, s *model.QuestSchedule, now time.Time) error {
+    last, err := time.Parse(time.RFC3339, s.NextRunAt)
+    if err != nil { return err }
+    claimed, err := r.Store.AdvanceQuestSchedule(ctx, s, Next(s.Recurrence, last, now))
+    if err != nil || !claimed { return err }
+    // Schedules of households without the entitlement are kept but paused.
+    if err := r.Plans.Schedule(ctx, s.ParentID); err != nil {
+        if apperr.Is(err, apperr.PlanLimit) { return nil }
+        return err
+    }
+    // Do not pile up copies of a chore the child has not done yet.
+    if s.LastAssignmentID != nil {
+        a, err := r.Store.GetAssignment(ctx, *s.LastAssignmentID)
+        if err != nil && !apperr.Is(err, apperr.NotFound) { return err }
+        if err == nil && a.Status != "COMPLETED" { return nil }
+    }
+    a, err := r.Store.AssignQuest(ctx, s.QuestID, s.ChildID)
+    if err != nil { return err }
+    return r.Store.SetScheduleAssignment(ctx, s, a.ID)
+}
+
+// Next returns the first run after now, stepping from last by the
+// recurrence's interval; runs missed while no scheduler ran are skipped.
+func Next(recurrence model.Recurrence, last, now time.Time) time.Time {
+    step := 24 * time.Hour
+    if recurrence == model.RecurrenceWeekly { step = 7 * 24 * time.Hour }
+    next := last.Add(step)
+    for !next.After(now) {
+        next = next.Add(step)
+    }
+    return next
+}
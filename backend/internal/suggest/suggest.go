@@ -0,0 +1,147 @@
+// Package suggest ranks quest templates for a child. It is a pure heuristic
+// over data the caller loads: how well a template suits the child's age,
+// whether the child did it recently and how many siblings did it lately.
+// Templates and quests match by title, ignoring case, since quests do not
+// remember the template they came from.
+package suggest
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+    "time"
+
+    "chorequest/backend/graph/model"
+)
+
+const (
+    // Recent is how far back completions count as recent.
+    Recent = 30 * 24 * time.Hour
+    // justDone makes a chore done within it score nothing for novelty.
+    justDone = 7 * 24 * time.Hour
+)
+
+// Weights of the three signals; without siblings their weight is spread over
+// the other two.
+const (
+    weightAge     = 0.5
+    weightNovelty = 0.3
+    weightSibling = 0.2
+)
+
+// Input is everything Rank looks at.
+type Input struct {
+    // Age is nil when the child's birthdate is unknown.
+    Age *int
+    // Templates are candidates; earlier ones win when titles repeat, so list
+    // the household's own templates before the catalog.
+    Templates []*model.QuestTemplate
+    // Quests are the household's quests, used to offer a quest ready to assign.
+    Quests []*model.Quest
+    // Assignments are the child's; Siblings holds each sibling's.
+    Assignments []*model.Assignment
+    Siblings    [][]*model.Assignment
+    Now         time.Time
+}
+
+// Rank returns up to limit suggestions, best first. Templates the child has
+// open already or is more than a year too young or old for are left out.
+func Rank(in Input, limit int) []*model.QuestSuggestion {
+    open, last := map[string]bool{}, map[string]time.Time{}
+    for _, a := range in.Assignments {
+        if a.Quest == nil { continue }
+        key := titleKey(a.Quest.Title)
+        if a.CompletedAt == nil {
+            open[key] = true
+            continue
+        }
+        if t, err := time.Parse(time.RFC3339, *a.CompletedAt); err == nil && t.After(last[key]) { last[key] = t }
+    }
+    siblingsDid := map[string]int{}
+    for _, as := range in.Siblings {
+        did := map[string]bool{}
+        for _, a := range as {
+            if a.Quest != nil && a.CompletedAt != nil && recent(*a.CompletedAt, in.Now, Recent) { did[titleKey(a.Quest.Title)] = true }
+        }
+        for key := range did {
+            siblingsDid[key]++
+        }
+    }
+    quests := map[string]string{}
+    for _, q := range in.Quests {
+        if _, ok := quests[titleKey(q.Title)]; !ok { quests[titleKey(q.Title)] = q.ID }
+    }
+
+    seen := map[string]bool{}
+    out := []*model.QuestSuggestion{}
+    for _, t := range in.Templates {
+        key := titleKey(t.Title)
+        if seen[key] || open[key] { continue }
+        seen[key] = true
+        ageScore, ageReason, ok := ageFit(t, in.Age)
+        if !ok { continue }
+        s := &model.QuestSuggestion{Template: t, Reasons: []string{}}
+        if ageReason != "" { s.Reasons = append(s.Reasons, ageReason) }
+
+        novelty := 1.0
+        switch done, ok := last[key]; {
+        case !ok:
+            s.Reasons = append(s.Reasons, "not done before")
+        case in.Now.Sub(done) < justDone:
+            novelty = 0
+            s.Reasons = append(s.Reasons, "done in the last week")
+        case in.Now.Sub(done) < Recent:
+            novelty = 0.5
+            s.Reasons = append(s.Reasons, "done in the last 30 days")
+        default:
+            s.Reasons = append(s.Reasons, "not done in the last 30 days")
+        }
+
+        score := ageScore*weightAge + novelty*weightNovelty
+        if n := len(in.Siblings); n > 0 {
+            did := siblingsDid[key]
+            score += float64(did) / float64(n) * weightSibling
+            if did > 0 { s.Reasons = append(s.Reasons, siblingReason(did, n)) }
+        } else {
+            score /= weightAge + weightNovelty
+        }
+        s.Score = math.Round(score*100) / 100
+        if id, ok := quests[key]; ok { s.QuestID = &id }
+        out = append(out, s)
+    }
+    sort.SliceStable(out, func(i, j int) bool {
+        if out[i].Score != out[j].Score { return out[i].Score > out[j].Score }
+        return out[i].Template.Title < out[j].Template.Title
+    })
+    if len(out) > limit { out = out[:limit] }
+    return out
+}
+
+// ageFit scores t for age: 1 within the template's band, 0.4 a year either
+// side, excluded beyond that. Unknown ages score 0.5 for every template.
+func ageFit(t *model.QuestTemplate, age *int) (float64, string, bool) {
+    switch {
+    case age == nil:
+        return 0.5, "", true
+    case *age >= t.MinAge && *age <= t.MaxAge:
+        return 1, fmt.Sprintf("suits age %d", *age), true
+    case *age == t.MinAge-1:
+        return 0.4, fmt.Sprintf("usually from age %d", t.MinAge), true
+    case *age == t.MaxAge+1:
+        return 0.4, fmt.Sprintf("usually up to age %d", t.MaxAge), true
+    }
+    return 0, "", false
+}
+
+func siblingReason(did, of int) string {
+    if of == 1 { return "done by their sibling in the last 30 days" }
+    return fmt.Sprintf("done by %d of %d siblings in the last 30 days", did, of)
+}
+
+func recent(at string, now time.Time, within time.Duration) bool {
+    t, err := time.Parse(time.RFC3339, at)
+    return err == nil && now.Sub(t) < within
+}
+
+func titleKey(title string) string { return strings.ToLower(strings.TrimSpace(title)) }
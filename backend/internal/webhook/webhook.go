@@ -0,0 +1,295 @@
+// Package webhook delivers domain events to parent-registered URLs as signed
+// JSON. Deliveries are recorded before sending and retried with exponential
+// backoff, so they survive restarts and can be inspected via GraphQL.
+package webhook
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+
+    "chorequest/backend/graph/model"
+    "chorequest/backend/internal/apperr"
+    "chorequest/backend/internal/events"
+    "chorequest/backend/internal/outbound"
+    repopkg "chorequest/backend/internal/repo"
+)
+
+// Headers sent with every delivery.
+const (
+    SignatureHeader = "X-Chorequest-Signature"
+    EventHeader     = "X-Chorequest-Event"
+    DeliveryHeader  = "X-Chorequest-Delivery"
+)
+
+// SecretPrefix marks signing secrets.
+const SecretPrefix = "whsec_"
+
+// eventTypes maps bus events to the webhook subscriptions that receive them.
+var eventTypes = map[events.Type]model.WebhookEvent{
+    events.AssignmentAssigned:  model.WebhookEventAssignmentAssigned,
+    events.AssignmentCompleted: model.WebhookEventAssignmentCompleted,
+    events.ItemPurchased:       model.WebhookEventItemPurchased,
+    events.RewardRedeemed:      model.WebhookEventRewardRedeemed,
+    events.LevelUp:             model.WebhookEventLevelUp,
+}
+
+// GenerateSecret returns a new signing secret.
+func GenerateSecret() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return SecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value for body sent at t:
+// "t=<unix>,v1=<hex HMAC-SHA256 of "<unix>.<body>">".
+func Sign(secret string, t time.Time, body []byte) string {
+    ts := strconv.FormatInt(t.Unix(), 10)
+    return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify checks a signature header against body, rejecting timestamps older
+// than tolerance. Receivers can use it as a reference implementation.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+    var ts, sig string
+    for _, part := range strings.Split(header, ",") {
+        k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+        switch k {
+        case "t":
+            ts = v
+        case "v1":
+            sig = v
+        }
+    }
+    unix, err := strconv.ParseInt(ts, 10, 64)
+    if err != nil || sig == "" {
+        return errors.New("malformed signature header")
+    }
+    if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+        return errors.New("signature timestamp too old")
+    }
+    if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, body))) {
+        return errors.New("signature mismatch")
+    }
+    return nil
+}
+
+func mac(secret, ts string, body []byte) string {
+    h := hmac.New(sha256.New, []byte(secret))
+    h.Write([]byte(ts))
+    h.Write([]byte("."))
+    h.Write(body)
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// Dispatcher turns events into delivery records and sends due deliveries.
+type Dispatcher struct {
+    Store  repopkg.Repo
+    Client *http.Client
+
+    MaxAttempts  int           // attempts before a delivery is dead-lettered
+    BaseBackoff  time.Duration // delay after the first failure; doubles each retry
+    MaxBackoff   time.Duration
+    PollInterval time.Duration
+    Timeout      time.Duration // per attempt
+
+    kick chan struct{}
+}
+
+// NewDispatcher returns a Dispatcher with production defaults: 8 attempts spread
+// over about an hour. Redirects are not followed and only public addresses are
+// dialled.
+func NewDispatcher(store repopkg.Repo) *Dispatcher {
+    return &Dispatcher{
+        Store: store,
+        Client: outbound.NewClient(0, true),
+        MaxAttempts:  8,
+        BaseBackoff:  30 * time.Second,
+        MaxBackoff:   time.Hour,
+        PollInterval: 5 * time.Second,
+        Timeout:      10 * time.Second,
+        kick:         make(chan struct{}, 1),
+    }
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+    tick := time.NewTicker(d.PollInterval)
+    defer tick.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-d.kick:
+        case <-tick.C:
+        }
+        d.sendDue(ctx)
+    }
+}
+
+func (d *Dispatcher) wake() {
+    select {
+    case d.kick <- struct{}{}:
+    default:
+    }
+}
+
+// Enqueue records one pending delivery per webhook of the household that
+// subscribed to e and wakes the sender. It is an outbox handler: delivery IDs
+// derive from the event and webhook, so repeated calls record nothing new.
+func (d *Dispatcher) Enqueue(ctx context.Context, e events.Event) error {
+    we, ok := eventTypes[e.Type]
+    if !ok {
+        return nil
+    }
+    defer d.wake()
+    hooks, err := d.Store.ListWebhooks(ctx, e.ParentID)
+    if err != nil || len(hooks) == 0 {
+        return err
+    }
+    payload, err := json.Marshal(e)
+    if err != nil {
+        return err
+    }
+    now := repopkg.NowRFC3339()
+    for _, h := range hooks {
+        if !subscribed(h, we) {
+            continue
+        }
+        del := &model.WebhookDelivery{
+            ID: uuid.NewSHA1(uuid.NameSpaceURL, []byte(e.ID+"/"+h.ID)).String(), WebhookID: h.ID, EventID: e.ID, Event: we,
+            Status: model.WebhookDeliveryStatusPending, CreatedAt: e.At, NextAttemptAt: &now,
+        }
+        if err := d.Store.CreateWebhookDelivery(ctx, del, payload); err != nil && !apperr.Is(err, apperr.Conflict) {
+            return err
+        }
+    }
+    return nil
+}
+
+func subscribed(h *model.Webhook, we model.WebhookEvent) bool {
+    for _, e := range h.Events {
+        if e == we {
+            return true
+        }
+    }
+    return false
+}
+
+const dueBatch = 25
+
+func (d *Dispatcher) sendDue(ctx context.Context) {
+    jobs, err := d.Store.DueWebhookDeliveries(ctx, time.Now(), dueBatch)
+    if err != nil {
+        log.Printf("webhook: poll: %v", err)
+        return
+    }
+    for _, j := range jobs {
+        // Lease past the attempt timeout so a crashed dispatcher's work is retried.
+        ok, err := d.Store.LeaseWebhookDelivery(ctx, j.Delivery, time.Now().Add(2*d.Timeout))
+        if err != nil {
+            log.Printf("webhook: lease %s: %v", j.Delivery.ID, err)
+            continue
+        }
+        if !ok {
+            continue
+        }
+        d.attempt(ctx, j)
+    }
+    if len(jobs) == dueBatch {
+        d.wake()
+    }
+}
+
+// attempt sends one delivery and records the outcome: SUCCEEDED on 2xx, a
+// scheduled retry otherwise, or DEAD once attempts are exhausted.
+func (d *Dispatcher) attempt(ctx context.Context, j *repopkg.WebhookJob) {
+    del := j.Delivery
+    del.Attempts++
+    status, err := d.send(ctx, j)
+    del.ResponseStatus = nil
+    if status != 0 {
+        del.ResponseStatus = &status
+    }
+    now := repopkg.NowRFC3339()
+    switch {
+    case err == nil:
+        del.Status = model.WebhookDeliveryStatusSucceeded
+        del.LastError, del.NextAttemptAt, del.DeliveredAt = nil, nil, &now
+    case apperr.Is(err, apperr.NotFound) || del.Attempts >= d.MaxAttempts:
+        msg := err.Error()
+        del.Status = model.WebhookDeliveryStatusDead
+        del.LastError, del.NextAttemptAt = &msg, nil
+    default:
+        msg := err.Error()
+        next := time.Now().Add(d.backoff(del.Attempts)).UTC().Format(time.RFC3339)
+        del.LastError, del.NextAttemptAt = &msg, &next
+    }
+    if err := d.Store.SaveWebhookDelivery(ctx, del); err != nil {
+        log.Printf("webhook: save %s: %v", del.ID, err)
+    }
+}
+
+func (d *Dispatcher) send(ctx context.Context, j *repopkg.WebhookJob) (int, error) {
+    hook, secret, err := d.Store.GetWebhook(ctx, j.Delivery.WebhookID)
+    if err != nil {
+        return 0, err
+    }
+    ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(j.Payload))
+    if err != nil {
+        return 0, err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("User-Agent", "Chorequest-Webhooks/1")
+    req.Header.Set(EventHeader, eventName(j.Delivery.Event))
+    req.Header.Set(DeliveryHeader, j.Delivery.ID)
+    req.Header.Set(SignatureHeader, Sign(secret, time.Now(), j.Payload))
+    resp, err := d.Client.Do(req)
+    if err != nil {
+        return 0, err
+    }
+    defer resp.Body.Close()
+    _, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+    }
+    return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+    b := d.BaseBackoff
+    for i := 1; i < attempts && b < d.MaxBackoff; i++ {
+        b *= 2
+    }
+    return min(b, d.MaxBackoff)
+}
+
+// eventName returns the dotted event name used in payloads, e.g. "level.up".
+func eventName(we model.WebhookEvent) string {
+    for t, e := range eventTypes {
+        if e == we {
+            return string(t)
+        }
+    }
+    return strings.ToLower(string(we))
+}
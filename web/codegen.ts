@@ -12,6 +12,10 @@ const config: CodegenConfig = {
     'src/gql/': {
       preset: 'client',
       plugins: [],
+      // Emits src/gql/persisted-documents.json, the backend's GRAPHQL_ALLOWLIST_FILE
+      presetConfig: {
+        persistedDocuments: true,
+      },
     },
   },
 }
@@ -1,5 +1,7 @@
 import { ApolloClient, InMemoryCache, HttpLink } from '@apollo/client'
 import { setContext } from '@apollo/client/link/context'
+import { RetryLink } from '@apollo/client/link/retry'
+import { getMainDefinition } from '@apollo/client/utilities'
 import { getToken } from './auth'
 
 const httpLink = new HttpLink({
@@ -16,7 +18,30 @@ const authLink = setContext(async (_, { headers }) => {
   }
 })
 
+// One key per mutation; retryLink below resends the same operation with the
+// same key, so the backend replays the first result instead of applying the
+// change twice. The backend refuses keys from anonymous callers.
+const idempotencyLink = setContext((request, prev) => {
+  const def = getMainDefinition(request.query)
+  if (def.kind !== 'OperationDefinition' || def.operation !== 'mutation') return {}
+  if (!prev.headers?.Authorization) return {}
+  const idempotencyKey: string = prev.idempotencyKey ?? crypto.randomUUID()
+  return {
+    idempotencyKey,
+    headers: {
+      ...prev.headers,
+      'Idempotency-Key': idempotencyKey,
+    },
+  }
+})
+
+// Retries network failures only; GraphQL errors are answers, not outages.
+const retryLink = new RetryLink({
+  delay: { initial: 500, max: 5000, jitter: true },
+  attempts: { max: 3 },
+})
+
 export const client = new ApolloClient({
-  link: authLink.concat(httpLink),
+  link: authLink.concat(idempotencyLink).concat(retryLink).concat(httpLink),
   cache: new InMemoryCache(),
 })
@@ -9,20 +9,40 @@ import Header from '../components/Header'
 import { Card, CardContent, CardHeader, CardTitle } from '../components/ui/Card'
 
 export default function Login(){
+  const [mode, setMode] = useState<'login'|'signup'|'dev'>(import.meta.env.DEV ? 'dev' : 'login')
   const [role, setRole] = useState<'PARENT'|'CHILD'>('PARENT')
   const [id, setId] = useState('parent-1')
+  const [email, setEmail] = useState('')
+  const [password, setPassword] = useState('')
   const setAuth = useAuth(s=>s.set)
   const [copyMsg, setCopyMsg] = useState<string>('')
+  const [mfaToken, setMfaToken] = useState<string | null>(null)
+  const [code, setCode] = useState('')
+  const [error, setError] = useState('')
 
   const doLogin = async () => {
-    const res = await fetch(`/auth/dev?role=${role}&sub=${encodeURIComponent(id)}`, { method: 'POST' })
+    setError('')
+    let res: Response
+    if (mfaToken) {
+      res = await fetch('/auth/mfa', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify({ mfaToken, code }) })
+      if (!res.ok) { setError('Invalid code'); return }
+    } else if (mode === 'dev') {
+      res = await fetch(`/auth/dev?role=${role}&sub=${encodeURIComponent(id)}`, { method: 'POST' })
+    } else {
+      res = await fetch(`/auth/${mode}`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify({ email, password }) })
+    }
+    if (!res.ok) { setError((await res.text()).trim() || 'Sign in failed'); return }
     const data = await res.json()
+    if (data.mfaRequired) { setMfaToken(data.mfaToken); return }
+    // Password logins are always parents; the server returns the parent ID as sub.
+    const sub: string = data.sub
+    const who = mode === 'dev' ? role : 'PARENT'
     await setToken(data.token)
-    setAuth(id, role)
-    if (role === 'PARENT') {
-      location.assign(`/parent/${id}`)
+    setAuth(sub, who)
+    if (who === 'PARENT') {
+      location.assign(`/parent/${sub}`)
     } else {
-      location.assign(`/child/${id}`)
+      location.assign(`/child/${sub}`)
     }
   }
 
@@ -40,23 +60,52 @@ export default function Login(){
             <Card className="w-full max-w-md">
               <CardHeader>
                 <CardTitle className="text-lg">Sign in</CardTitle>
-                <p className="text-sm text-zinc-600 mt-1">Dev login issues a JWT via backend</p>
+                <p className="text-sm text-zinc-600 mt-1">{mode === 'dev' ? 'Dev login issues a JWT via backend' : mode === 'signup' ? 'Create a parent account' : 'Parents sign in with email and password'}</p>
               </CardHeader>
               <CardContent>
                 <form className="space-y-3" onSubmit={e=>{e.preventDefault(); doLogin()}}>
-                  <div>
-                    <Label className="mb-1">Role</Label>
-                    <Select value={role} onChange={e=>setRole(e.target.value as any)}>
-                      <option value="PARENT">Parent</option>
-                      <option value="CHILD">Child</option>
-                    </Select>
-                  </div>
-                  <div>
-                    <Label className="mb-1">ID</Label>
-                    <Input value={id} onChange={e=>setId(e.target.value)} placeholder="parent-1 or &lt;childId&gt;"/>
-                  </div>
-                  <Button type="submit" className="w-full">Continue</Button>
+                  {mode === 'dev' ? (
+                    <>
+                      <div>
+                        <Label className="mb-1">Role</Label>
+                        <Select value={role} onChange={e=>setRole(e.target.value as any)}>
+                          <option value="PARENT">Parent</option>
+                          <option value="CHILD">Child</option>
+                        </Select>
+                      </div>
+                      <div>
+                        <Label className="mb-1">ID</Label>
+                        <Input value={id} onChange={e=>setId(e.target.value)} placeholder="parent-1 or &lt;childId&gt;"/>
+                      </div>
+                    </>
+                  ) : (
+                    <>
+                      <div>
+                        <Label className="mb-1">Email</Label>
+                        <Input type="email" value={email} onChange={e=>setEmail(e.target.value)} autoComplete="email"/>
+                      </div>
+                      <div>
+                        <Label className="mb-1">Password</Label>
+                        <Input type="password" value={password} onChange={e=>setPassword(e.target.value)} autoComplete={mode === 'signup' ? 'new-password' : 'current-password'}/>
+                      </div>
+                    </>
+                  )}
+                  {mfaToken && (
+                    <div>
+                      <Label className="mb-1">Authenticator code</Label>
+                      <Input value={code} onChange={e=>setCode(e.target.value)} placeholder="123456 or recovery code" autoFocus/>
+                    </div>
+                  )}
+                  {error && <div className="text-xs text-red-600">{error}</div>}
+                  <Button type="submit" className="w-full">{mfaToken ? 'Verify' : mode === 'signup' ? 'Create account' : 'Continue'}</Button>
                 </form>
+                {!mfaToken && (
+                  <div className="flex gap-3 justify-center mt-3 text-xs">
+                    {mode !== 'login' && <button type="button" className="text-indigo-600" onClick={()=>setMode('login')}>Sign in with email</button>}
+                    {mode !== 'signup' && <button type="button" className="text-indigo-600" onClick={()=>setMode('signup')}>Create an account</button>}
+                    {import.meta.env.DEV && mode !== 'dev' && <button type="button" className="text-indigo-600" onClick={()=>setMode('dev')}>Dev login</button>}
+                  </div>
+                )}
               </CardContent>
             </Card>
           </div>